@@ -22,9 +22,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
 	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/oidc"
+	"github.com/qullDev/BookMyField/internal/payments"
 	"github.com/qullDev/BookMyField/internal/routes"
 	"github.com/qullDev/BookMyField/internal/seed"
+	"github.com/qullDev/BookMyField/internal/workers"
 
 	_ "github.com/qullDev/BookMyField/cmd/api/docs" // docs is generated by Swag CLI
 	swaggerFiles "github.com/swaggo/files"
@@ -41,18 +45,50 @@ func main() {
 	config.ConnectDatabse()
 	config.InitRedis()
 	config.InitStripe()
-
-	err := config.DB.AutoMigrate(&models.User{}, &models.Field{}, &models.Booking{}, &models.Payment{})
+	payments.Init()
+	config.InitTimezone()
+	config.InitCurrency()
+	config.InitBookingHold()
+	config.InitWaitlist()
+	config.InitReconcile()
+	config.InitInvoice()
+	config.InitAuth()
+	mailer.Init()
+	oidc.Init()
+
+	backfillVerified := config.UsersPredateEmailVerification()
+	backfillWalletRefunds := config.PaymentsPredateWalletRefunds()
+	config.DedupeFieldClosures()
+	err := config.DB.AutoMigrate(&models.User{}, &models.Field{}, &models.Booking{}, &models.Payment{},
+		&models.FieldOpeningHour{}, &models.FieldClosure{}, &models.BookingPriceItem{},
+		&models.PricingRule{}, &models.BookingSeries{},
+		&models.CancellationPolicy{}, &models.WaitlistEntry{}, &models.WebhookEvent{}, &models.Coupon{},
+		&models.Invoice{}, &models.InvoiceCounter{},
+		&models.Wallet{}, &models.WalletEntry{}, &models.WalletTopUp{}, &models.BookingShare{},
+		&models.UserToken{}, &models.UserIdentity{}, &models.OIDCLogin{},
+		&models.RecoveryCode{})
 	if err != nil {
 		log.Fatal("Error migrating database:", err.Error())
 		return
 	}
+	config.MigrateBookingConstraints()
+	config.MigrateCancellationPolicyConstraints()
+	if backfillVerified {
+		config.BackfillEmailVerification()
+	}
+	if backfillWalletRefunds {
+		config.BackfillWalletRefunds()
+	}
 
 	// seed data
 	seed.SeedAdminUser()
 	seed.SeedFields()
 	seed.SeedRegularUser()
 
+	// background jobs
+	workers.StartBookingExpiryWorker()
+	workers.StartReconciliationWorker()
+
 	// Route
 	api_v1 := r.Group("/api/v1")
 	{
@@ -60,6 +96,11 @@ func main() {
 		routes.BookingsRoutes(api_v1)
 		routes.FieldRoutes(api_v1)
 		routes.PaymentRoutes(api_v1)
+		routes.CancellationPolicyRoutes(api_v1)
+		routes.WaitlistRoutes(api_v1)
+		routes.CouponRoutes(api_v1)
+		routes.WalletRoutes(api_v1)
+		routes.ShareRoutes(api_v1)
 		routes.HealthRoute(api_v1)
 	}
 
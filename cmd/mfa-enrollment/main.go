@@ -0,0 +1,39 @@
+// Command mfa-enrollment prints a two-factor enrollment token for an account
+// whose email cannot receive the one sent on setup, such as the seeded
+// admin. Call POST /auth/mfa/setup first, then pass the printed token to
+// POST /auth/mfa/enable. It uses the same environment as the API.
+//
+//	go run ./cmd/mfa-enrollment -email admin@admin.com
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	email := flag.String("email", "", "email address of the account enrolling in two-factor authentication")
+	flag.Parse()
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+
+	config.ConnectDatabse()
+	config.InitRedis()
+	config.InitAuth()
+
+	token, err := services.IssueMFAEnrollmentToken(config.DB, *email)
+	if err != nil {
+		log.Fatalf("Failed to issue enrollment token for %s: %v", *email, err)
+	}
+	fmt.Printf("Enrollment token for %s, valid for %s:\n%s\n", *email, config.MFAEnrollmentTTL, token)
+}
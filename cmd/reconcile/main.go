@@ -0,0 +1,80 @@
+// Command reconcile compares pending and succeeded payments with their
+// payment provider once, fixes drifted payment and booking statuses and
+// prints a discrepancy report. It uses the same environment as the API.
+//
+//	go run ./cmd/reconcile -days 7 -dry-run
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	config.InitReconcile()
+	days := flag.Int("days", config.ReconcileDays, "check payments updated in the last N days")
+	dryRun := flag.Bool("dry-run", false, "report discrepancies without fixing them")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	config.ConnectDatabse()
+	config.InitRedis()
+	config.InitStripe()
+	payments.Init()
+	config.InitTimezone()
+	config.InitCurrency()
+
+	report, err := services.Reconcile(config.DB, time.Now().AddDate(0, 0, -*days), *dryRun)
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		printReport(report)
+	}
+
+	// Non-zero exit for cron alerts when something needs a human
+	if report.Unresolved() > 0 && !*dryRun {
+		os.Exit(2)
+	}
+}
+
+func printReport(report *services.ReconcileReport) {
+	mode := ""
+	if report.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Printf("Payment reconciliation%s: %d sessions checked, %d discrepancies, %d unresolved\n\n",
+		mode, report.Checked, len(report.Discrepancies), report.Unresolved())
+	if len(report.Discrepancies) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tPROVIDER\tPAYMENTS\tLOCAL\tPROVIDER STATUS\tISSUE\tACTION\tFIXED")
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%t\n",
+			d.SessionID, d.Provider, len(d.PaymentIDs), d.LocalStatus, d.ProviderStatus, d.Issue, d.Action, d.Fixed)
+	}
+	w.Flush()
+}
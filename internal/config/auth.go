@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// EmailVerificationTTL is how long an email verification link is valid.
+var EmailVerificationTTL = 24 * time.Hour
+
+// EmailVerificationURL is the page the verification email links to, with
+// the token appended as the token query parameter. It defaults to the
+// verify-email endpoint of this API.
+var EmailVerificationURL = "http://localhost:8080/api/v1/auth/verify-email"
+
+// PasswordResetTTL is how long an emailed password reset token is valid.
+var PasswordResetTTL = time.Hour
+
+// PasswordResetURL is the page of the frontend that resets a password; the
+// token is appended as the token query parameter. Without it the email
+// contains the token itself.
+var PasswordResetURL = ""
+
+// OIDCLoginRedirectURL is the frontend page a successful OIDC login
+// redirects to, with the tokens in the URL fragment. Without it the callback
+// responds with the tokens as JSON, like a password login.
+var OIDCLoginRedirectURL = ""
+
+// MFARequiredRoles are the roles that must sign in with two-factor
+// authentication. Their users can only enroll in it until they do.
+var MFARequiredRoles = map[string]bool{}
+
+// MFAEnrollmentTTL is how long the token emailed to confirm enrolling in
+// required two-factor authentication is valid.
+var MFAEnrollmentTTL = 30 * time.Minute
+
+// MFAIssuer is the account issuer shown in authenticator apps.
+var MFAIssuer = "BookMyField"
+
+// MFARequired reports whether users with role must use two-factor
+// authentication.
+func MFARequired(role string) bool {
+	return MFARequiredRoles[role]
+}
+
+func InitAuth() {
+	PasswordResetTTL = minutesFromEnv("PASSWORD_RESET_TTL_MINUTES", PasswordResetTTL)
+	PasswordResetURL = os.Getenv("PASSWORD_RESET_URL")
+
+	EmailVerificationTTL = minutesFromEnv("EMAIL_VERIFICATION_TTL_MINUTES", EmailVerificationTTL)
+	if v := os.Getenv("EMAIL_VERIFICATION_URL"); v != "" {
+		EmailVerificationURL = v
+	} else if base := os.Getenv("APP_BASE_URL"); base != "" {
+		EmailVerificationURL = strings.TrimRight(base, "/") + "/api/v1/auth/verify-email"
+	}
+
+	OIDCLoginRedirectURL = os.Getenv("OIDC_LOGIN_REDIRECT_URL")
+
+	for _, role := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			MFARequiredRoles[role] = true
+		}
+	}
+	MFAEnrollmentTTL = minutesFromEnv("MFA_ENROLLMENT_TTL_MINUTES", MFAEnrollmentTTL)
+	if v := os.Getenv("MFA_ISSUER"); v != "" {
+		MFAIssuer = v
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// BookingHold is how long a pending booking blocks its slot while waiting for payment.
+var BookingHold = 15 * time.Minute
+
+// SplitBookingHold is how long a booking split between participants is held,
+// counted from the split, so everyone has time to pay their share. It never
+// extends past the start of the booking.
+var SplitBookingHold = 24 * time.Hour
+
+// WaitlistOfferHold is how long a freed slot offered to a waitlisted user is held for them.
+var WaitlistOfferHold = 30 * time.Minute
+
+func InitBookingHold() {
+	BookingHold = minutesFromEnv("BOOKING_HOLD_MINUTES", BookingHold)
+	SplitBookingHold = minutesFromEnv("SPLIT_BOOKING_HOLD_MINUTES", SplitBookingHold)
+	log.Printf("✅ Unpaid bookings are held for %s, split bookings for %s", BookingHold, SplitBookingHold)
+}
+
+func InitWaitlist() {
+	WaitlistOfferHold = minutesFromEnv("WAITLIST_OFFER_MINUTES", WaitlistOfferHold)
+	log.Printf("✅ Waitlist offers are held for %s", WaitlistOfferHold)
+}
+
+// minutesFromEnv reads a positive number of minutes, falling back to def.
+func minutesFromEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	minutes, err := strconv.Atoi(v)
+	if err != nil || minutes <= 0 {
+		log.Printf("⚠️ Invalid %s %q, using default %s", name, v, def)
+		return def
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// Currency is the ISO 4217 currency (lowercase, as Stripe expects) used for
+// field prices, booking totals and checkout sessions.
+var Currency = "idr"
+
+func InitCurrency() {
+	if c := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_CURRENCY"))); c != "" {
+		Currency = c
+	}
+	log.Printf("✅ Payment currency set to %s", strings.ToUpper(Currency))
+}
@@ -21,10 +21,10 @@ func ConnectDatabse() {
 	if dsn == "" {
 		// Development fallback - use SQLite
 		log.Println("⚠️ DATABASE_URL not found, using SQLite for development")
-		db, err = gorm.Open(sqlite_driver.Open("bookmyfield.db"), &gorm.Config{})
+		db, err = gorm.Open(sqlite_driver.Open("bookmyfield.db"), &gorm.Config{TranslateError: true})
 	} else {
 		// Production - use PostgreSQL
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	}
 
 	if err != nil {
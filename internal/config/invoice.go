@@ -0,0 +1,40 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// Seller details and tax printed on receipts. Prices are tax inclusive; the
+// tax line shows the part of the amount that is tax.
+var (
+	InvoicePrefix  = "INV"
+	CompanyName    = "BookMyField"
+	CompanyAddress = ""
+	CompanyTaxID   = ""
+	TaxRate        = 0.0 // percent, e.g. 11 for PPN
+	TaxName        = "Tax"
+)
+
+func InitInvoice() {
+	if v := os.Getenv("INVOICE_PREFIX"); v != "" {
+		InvoicePrefix = v
+	}
+	if v := os.Getenv("COMPANY_NAME"); v != "" {
+		CompanyName = v
+	}
+	CompanyAddress = os.Getenv("COMPANY_ADDRESS")
+	CompanyTaxID = os.Getenv("COMPANY_TAX_ID")
+	if v := os.Getenv("TAX_NAME"); v != "" {
+		TaxName = v
+	}
+	if v := os.Getenv("TAX_RATE_PERCENT"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate >= 100 {
+			log.Printf("⚠️ Invalid TAX_RATE_PERCENT %q, using %g", v, TaxRate)
+		} else {
+			TaxRate = rate
+		}
+	}
+}
@@ -9,14 +9,24 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateAccessToken(userID, role string) (string, int64, error) {
+// GenerateAccessToken signs an access token. sessionID is the session it
+// belongs to (empty when sessions are not tracked) and mfa records that the
+// user signed in with a second factor.
+func GenerateAccessToken(userID, role, sessionID string, mfa bool) (string, int64, error) {
 	exp := time.Now().Add(time.Hour * 24).Unix()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
 		"exp":     exp,
-	})
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+	if mfa {
+		claims["mfa"] = true
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
@@ -0,0 +1,130 @@
+package config
+
+import "log"
+
+// UsersPredateEmailVerification reports whether the users table exists
+// without the email_verified_at column, i.e. its accounts were created
+// before email verification. Call it before AutoMigrate adds the column.
+func UsersPredateEmailVerification() bool {
+	m := DB.Migrator()
+	return m.HasTable("users") && !m.HasColumn("users", "email_verified_at")
+}
+
+// BackfillEmailVerification marks the existing accounts as verified so they
+// are not locked out of booking when email verification is introduced.
+func BackfillEmailVerification() {
+	res := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL")
+	if res.Error != nil {
+		log.Printf("⚠️ Failed to mark existing users as verified: %v", res.Error)
+		return
+	}
+	log.Printf("✅ %d existing users marked as verified", res.RowsAffected)
+}
+
+// PaymentsPredateWalletRefunds reports whether the payments table exists
+// without the wallet_refunded_amount column, i.e. refunds credited to the
+// wallet before it was added are only part of refunded_amount. Call it before
+// AutoMigrate adds the column.
+func PaymentsPredateWalletRefunds() bool {
+	m := DB.Migrator()
+	return m.HasTable("payments") && !m.HasColumn("payments", "wallet_refunded_amount")
+}
+
+// BackfillWalletRefunds records the refunds credited to wallets so far, as
+// found in the wallet ledger, on their payments.
+func BackfillWalletRefunds() {
+	res := DB.Exec(`
+UPDATE payments SET wallet_refunded_amount = (
+	SELECT SUM(e.amount) FROM wallet_entries AS e
+	WHERE e.payment_id = payments.id AND e.kind = 'refund' AND e.account = 'wallet'
+)
+WHERE id IN (SELECT payment_id FROM wallet_entries WHERE kind = 'refund' AND account = 'wallet')`)
+	if res.Error != nil {
+		log.Printf("⚠️ Failed to backfill wallet refunds: %v", res.Error)
+		return
+	}
+	log.Printf("✅ Wallet refunds recorded on %d payments", res.RowsAffected)
+}
+
+// MigrateBookingConstraints adds database-level protection against double
+// bookings. On PostgreSQL an exclusion constraint rejects overlapping
+// non-cancelled bookings of the same field; other databases rely on the
+// application-level lock in services.WithSlotLock.
+func MigrateBookingConstraints() {
+	if DB.Dialector.Name() != "postgres" {
+		return
+	}
+
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS btree_gist").Error; err != nil {
+		log.Printf("⚠️ Failed to enable btree_gist, double booking protection relies on locks only: %v", err)
+		return
+	}
+
+	err := DB.Exec(`
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'bookings_no_overlap') THEN
+		ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+			EXCLUDE USING gist (field_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+			WHERE (status <> 'cancelled');
+	END IF;
+END $$;`).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to add bookings_no_overlap constraint (overlapping bookings present?): %v", err)
+		return
+	}
+
+	log.Println("✅ Booking overlap constraint ensured")
+}
+
+// DedupeFieldClosures removes duplicate closures of the same field and date,
+// keeping the oldest, so the unique index on field_closures can be created.
+// Call it before AutoMigrate adds the index.
+func DedupeFieldClosures() {
+	m := DB.Migrator()
+	if !m.HasTable("field_closures") || m.HasIndex("field_closures", "idx_field_closures_field_date") {
+		return
+	}
+
+	res := DB.Exec(`
+DELETE FROM field_closures AS a
+WHERE EXISTS (
+	SELECT 1 FROM field_closures AS b
+	WHERE b.field_id = a.field_id AND b.date = a.date
+		AND (b.created_at < a.created_at OR (b.created_at = a.created_at AND b.id < a.id))
+)`)
+	if res.Error != nil {
+		log.Printf("⚠️ Failed to remove duplicate field closures: %v", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("✅ %d duplicate field closures removed", res.RowsAffected)
+	}
+}
+
+// MigrateCancellationPolicyConstraints adds a partial unique index allowing a
+// single global cancellation policy (field_id IS NULL), which the unique
+// index on field_id cannot enforce. Duplicates created before are removed
+// first, keeping the most recently updated one.
+func MigrateCancellationPolicyConstraints() {
+	res := DB.Exec(`
+DELETE FROM cancellation_policies
+WHERE field_id IS NULL AND id NOT IN (
+	SELECT id FROM (
+		SELECT id FROM cancellation_policies WHERE field_id IS NULL ORDER BY updated_at DESC, id LIMIT 1
+	) AS latest
+)`)
+	if res.Error != nil {
+		log.Printf("⚠️ Failed to remove duplicate global cancellation policies: %v", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("✅ %d duplicate global cancellation policies removed", res.RowsAffected)
+	}
+
+	err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policies_global
+	ON cancellation_policies ((field_id IS NULL)) WHERE field_id IS NULL`).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to add idx_cancellation_policies_global: %v", err)
+	}
+}
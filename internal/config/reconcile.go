@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// ReconcileHour is the hour of the day (in Location) the nightly payment
+// reconciliation runs; -1 disables it.
+var ReconcileHour = 3
+
+// ReconcileDays is how far back reconciliation looks at payments.
+var ReconcileDays = 30
+
+func InitReconcile() {
+	if v := os.Getenv("RECONCILE_HOUR"); v != "" {
+		hour, err := strconv.Atoi(v)
+		if err != nil || hour < -1 || hour > 23 {
+			log.Printf("⚠️ Invalid RECONCILE_HOUR %q, using default %d", v, ReconcileHour)
+		} else {
+			ReconcileHour = hour
+		}
+	}
+	if v := os.Getenv("RECONCILE_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			log.Printf("⚠️ Invalid RECONCILE_DAYS %q, using default %d", v, ReconcileDays)
+		} else {
+			ReconcileDays = days
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+	_ "time/tzdata" // embed tz database so APP_TIMEZONE works on minimal images
+)
+
+// Location is the timezone used to interpret calendar dates (availability, opening hours, etc).
+var Location = time.UTC
+
+func InitTimezone() {
+	name := os.Getenv("APP_TIMEZONE")
+	if name == "" {
+		name = "Asia/Jakarta"
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️ Invalid APP_TIMEZONE %q: %v. Falling back to UTC.", name, err)
+		return
+	}
+
+	Location = loc
+	log.Printf("✅ Timezone set to %s", name)
+}
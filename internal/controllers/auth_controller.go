@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -9,7 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
 	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -17,7 +21,7 @@ var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user with name, email, and password
+// @Description Register a new user with name, email, and password. A link verifying the email address is emailed to the user; unverified users cannot book or pay.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -67,12 +71,17 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	// The link can be sent again with /auth/verify-email/resend
+	if err := services.SendEmailVerification(config.DB, &user); err != nil {
+		log.Printf("⚠️ Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, check your email to verify your address"})
 }
 
 // Login godoc
 // @Summary Log in a user
-// @Description Log in a user with email and password
+// @Description Log in a user with email and password. Users with two-factor authentication get an MFA challenge (dto.MFAChallengeResponse) instead of tokens, to complete at /auth/mfa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -106,33 +115,61 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate access token
-	accessToken, exp, err := config.GenerateAccessToken(user.ID.String(), user.Role)
+	if res, ok := signIn(c, &user); ok {
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// signIn completes the first factor of a login: it issues tokens, or an MFA
+// challenge for users with two-factor authentication. On failure it writes
+// the error response.
+func signIn(c *gin.Context, user *models.User) (any, bool) {
+	if !services.MFAEnabled(user) {
+		return issueTokens(c, user, false)
+	}
+	token, err := services.StartMFAChallenge(config.DB, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
-		return
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor authentication"})
+		return nil, false
 	}
+	return &dto.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int64(services.MFAChallengeTTL / time.Second),
+	}, true
+}
 
-	// Generate refresh token
-	refreshToken := uuid.NewString()
-	if config.RedisClient != nil {
-		err = config.RedisClient.Set(config.Ctx, "refresh:"+refreshToken, user.ID.String(), time.Hour*24*7).Err()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store refresh token"})
-			return
-		}
+// issueTokens starts a session for a user who just signed in, with mfa
+// telling whether a second factor was used, and creates its access and
+// refresh token. On failure it writes the error response.
+func issueTokens(c *gin.Context, user *models.User, mfa bool) (*dto.LoginResponse, bool) {
+	sessionID, refreshToken, err := services.CreateSession(user.ID.String(), mfa, sessionClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store refresh token"})
+		return nil, false
+	}
+	return sessionTokens(c, user, sessionID, mfa, refreshToken)
+}
+
+// sessionTokens creates the access token of a session and returns it with
+// the session's refresh token. On failure it writes the error response.
+func sessionTokens(c *gin.Context, user *models.User, sessionID string, mfa bool, refreshToken string) (*dto.LoginResponse, bool) {
+	accessToken, exp, err := config.GenerateAccessToken(user.ID.String(), user.Role, sessionID, mfa)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"access_token":  accessToken,
-		"expires_in":    exp,
-		"refresh_token": refreshToken,
-	})
+	return &dto.LoginResponse{
+		AccessToken:  accessToken,
+		ExpiresIn:    exp,
+		RefreshToken: refreshToken,
+	}, true
 }
 
 // Logout godoc
 // @Summary Log out a user
-// @Description Log out a user by blacklisting access token and deleting refresh token
+// @Description Log out a user by blacklisting access token and ending the session of the refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -155,9 +192,9 @@ func Logout(c *gin.Context) {
 		return
 	}
 
-	// hapus refresh token dari Redis
-	if config.RedisClient != nil {
-		config.RedisClient.Del(config.Ctx, "refresh:"+body.RefreshToken)
+	// akhiri session dari refresh token
+	if err := services.RevokeRefreshToken(body.RefreshToken); err != nil {
+		log.Printf("⚠️ Failed to revoke session on logout: %v", err)
 	}
 
 	// blacklist access token sampai expired
@@ -172,7 +209,7 @@ func Logout(c *gin.Context) {
 
 // Refresh godoc
 // @Summary Refresh access token
-// @Description Refresh access token using a refresh token
+// @Description Refresh access token using a refresh token. The refresh token is rotated: the response holds a new one and the old one stops working. Using an old refresh token again revokes its whole session.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -191,45 +228,155 @@ func Refresh(c *gin.Context) {
 		return
 	}
 
-	// Check refresh token in Redis
-	if config.RedisClient == nil {
+	// Rotate refresh token (old one is marked used, new one is returned)
+	session, refreshToken, err := services.RotateRefreshToken(body.RefreshToken, sessionClient(c))
+	switch {
+	case errors.Is(err, services.ErrSessionsDisabled):
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Redis not available"})
 		return
-	}
-
-	userID, err := config.RedisClient.Get(config.Ctx, "refresh:"+body.RefreshToken).Result()
-	if err != nil || userID == "" {
+	case errors.Is(err, services.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used, the session has been revoked"})
+		return
+	case err != nil:
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token revoked or expired"})
 		return
 	}
 
 	// Get user role and validate user exists
 	var user models.User
-	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
-		// If user not found, delete the refresh token for security
-		config.RedisClient.Del(config.Ctx, "refresh:"+body.RefreshToken)
+	if err := config.DB.First(&user, "id = ?", session.UserID).Error; err != nil {
+		// If user not found, end the session for security
+		services.RevokeSession(session.UserID, session.ID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
 
-	// Generate new access token
-	accessToken, exp, err := config.GenerateAccessToken(user.ID.String(), user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
+	if tokens, ok := sessionTokens(c, &user, session.ID, session.MFA, refreshToken); ok {
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+// sessionClient describes the client of a request for its session.
+func sessionClient(c *gin.Context) services.SessionClient {
+	return services.SessionClient{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset token to the account with this email. The response is the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body dto.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *gin.Context) {
+	var input dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.RequestPasswordReset(config.DB, input.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create password reset token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with a token from the password reset email. The token can be used once. All refresh tokens of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	var input dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Rotate refresh token (delete old, create new)
-	config.RedisClient.Del(config.Ctx, "refresh:"+body.RefreshToken)
-	newRefresh := uuid.NewString()
-	if err := config.RedisClient.Set(config.Ctx, "refresh:"+newRefresh, user.ID.String(), time.Hour*24*7).Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store new refresh token"})
+	if err := services.ResetPassword(config.DB, input.Token, input.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset token is invalid or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Verify the email address of an account with the token from the verification email. The token can be used once.
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/verify-email [get]
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := services.VerifyEmail(config.DB, token); err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link is invalid or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email
+// @Description Send the current user a new email verification link. Earlier links stop working.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/verify-email/resend [post]
+func ResendVerificationEmail(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", uid).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := services.SendEmailVerification(config.DB, &user); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already verified"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"access_token":  accessToken,
-		"expires_in":    exp,
-		"refresh_token": newRefresh,
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
 }
@@ -1,16 +1,17 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
 	"github.com/qullDev/BookMyField/internal/models"
-	"github.com/stripe/stripe-go/v76"
-	"github.com/stripe/stripe-go/v76/checkout/session"
-	stripeRefund "github.com/stripe/stripe-go/v76/refund"
+	"github.com/qullDev/BookMyField/internal/services"
+	"gorm.io/gorm"
 )
 
 type CreateBookingInput struct {
@@ -18,6 +19,8 @@ type CreateBookingInput struct {
 	StartTime time.Time `json:"start_time" binding:"required"`
 	EndTime   time.Time `json:"end_time" binding:"required"`
 	Notes     string    `json:"notes,omitempty"`
+
+	Recurrence *dto.RecurrenceRequest `json:"recurrence,omitempty"`
 }
 
 // GetBookings godoc
@@ -37,6 +40,7 @@ func GetBookings(c *gin.Context) {
 		Preload("User").
 		Preload("Field").
 		Preload("Payments").
+		Preload("PriceItems").
 		Find(&bookings).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -46,7 +50,8 @@ func GetBookings(c *gin.Context) {
 
 // CreateBooking godoc
 // @Summary Create a new booking
-// @Description Create a new booking for a field.
+// @Description Create a new booking for a field. The total price is computed from the field's hourly price and stored with a breakdown. The booking stays pending until paid and is cancelled automatically when the payment hold (expires_at) passes.
+// @Description With a recurrence, a weekly or biweekly series is created instead: free occurrences are booked and the others are reported as conflicts (response 201 is then a dto.CreateBookingSeriesResponse).
 // @Tags bookings
 // @Security BearerAuth
 // @Accept json
@@ -55,6 +60,7 @@ func GetBookings(c *gin.Context) {
 // @Success 201 {object} models.Booking
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -97,18 +103,29 @@ func CreateBooking(c *gin.Context) {
 		return
 	}
 
-	// Check for conflicting bookings
-	var conflictCount int64
-	config.DB.Model(&models.Booking{}).
-		Where("field_id = ? AND status != ? AND ((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?))",
-			fieldID, "cancelled", input.StartTime, input.StartTime, input.EndTime, input.EndTime).
-		Count(&conflictCount)
+	// Recurring bookings validate and book every occurrence separately
+	if input.Recurrence != nil {
+		createBookingSeries(c, uid, &field, &input)
+		return
+	}
 
-	if conflictCount > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Field is already booked for this time slot"})
+	// Validate opening hours and closures
+	if err := services.CheckOpeningHours(config.DB, fieldID, input.StartTime, input.EndTime); err != nil {
+		if errors.Is(err, services.ErrFieldClosed) || errors.Is(err, services.ErrOutsideOpeningHours) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate opening hours"})
 		return
 	}
 
+	quote, err := services.QuoteBooking(config.DB, &field, input.StartTime, input.EndTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holdUntil := time.Now().Add(config.BookingHold)
 	booking := models.Booking{
 		ID:        uuid.New(),
 		UserID:    uid,
@@ -117,9 +134,16 @@ func CreateBooking(c *gin.Context) {
 		EndTime:   input.EndTime,
 		Status:    "pending",
 		Notes:     input.Notes,
+		ExpiresAt: &holdUntil,
 	}
+	services.ApplyQuote(&booking, quote)
 
-	if err := config.DB.Create(&booking).Error; err != nil {
+	// Conflict check and insert are atomic, see services.CreateBooking
+	if err := services.CreateBooking(config.DB, &booking); err != nil {
+		if errors.Is(err, services.ErrSlotTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Field is already booked for this time slot"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
 		return
 	}
@@ -129,6 +153,7 @@ func CreateBooking(c *gin.Context) {
 		Preload("User").
 		Preload("Field").
 		Preload("Payments").
+		Preload("PriceItems").
 		First(&booking, "id = ?", booking.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking details"})
 		return
@@ -154,6 +179,7 @@ func GetMyBookings(c *gin.Context) {
 	if err := config.DB.
 		Preload("Field").
 		Preload("Payments").
+		Preload("PriceItems").
 		Where("user_id = ?", userID).
 		Find(&bookings).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
@@ -163,17 +189,115 @@ func GetMyBookings(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+// RescheduleBooking godoc
+// @Summary Reschedule a booking
+// @Description Move a pending, partially paid or confirmed booking to a new time, optionally on another field. The new slot is validated like a new booking and the booking is repriced.
+// @Description For a paid booking a higher price opens a top-up checkout session and a lower price is partially refunded.
+// @Description The difference is settled after the move; if that fails (502) the booking stays moved and rescheduling it to the same time again settles what is left.
+// @Tags bookings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param input body dto.RescheduleBookingRequest true "New booking time"
+// @Success 200 {object} dto.RescheduleBookingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Router /bookings/{id} [patch]
+func RescheduleBooking(c *gin.Context) {
+	var req dto.RescheduleBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var booking models.Booking
+	if err := config.DB.Preload("Field").Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&booking, "id = ? AND user_id = ?", c.Param("id"), userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	field := booking.Field
+	if req.FieldID != "" {
+		fieldID, err := uuid.Parse(req.FieldID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field_id format"})
+			return
+		}
+		field = models.Field{}
+		if err := config.DB.First(&field, "id = ?", fieldID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+			return
+		}
+	}
+
+	// Validate time
+	if !req.StartTime.Before(req.EndTime) || req.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking time"})
+		return
+	}
+
+	result, err := services.RescheduleBooking(config.DB, &booking, &field, req.StartTime, req.EndTime)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSettlementFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSlotTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Field is already booked for this time slot"})
+		case errors.Is(err, services.ErrCheckoutCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNotReschedulable),
+			errors.Is(err, services.ErrBookingSplit),
+			errors.Is(err, services.ErrFieldClosed),
+			errors.Is(err, services.ErrOutsideOpeningHours):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking: " + err.Error()})
+		}
+		return
+	}
+
+	if err := config.DB.
+		Preload("User").
+		Preload("Field").
+		Preload("Payments").
+		Preload("PriceItems").
+		First(&booking, "id = ?", booking.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking details"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RescheduleBookingResponse{
+		Booking:           booking,
+		PreviousTotal:     result.PreviousTotal,
+		AmountDue:         result.AmountDue,
+		CheckoutSessionID: result.CheckoutID,
+		CheckoutURL:       result.CheckoutURL,
+		RefundedAmount:    result.RefundedAmount,
+		RefundID:          result.RefundID,
+	})
+}
+
 // CancelBooking godoc
 // @Summary Cancel a booking
-// @Description Cancel a booking by its ID. If payment exists, it will be refunded.
+// @Description Cancel a booking by its ID. If payment exists, it is refunded according to the cancellation policy of the field (or the global policy); without a policy the refund is full. With refund_to=wallet the refund is credited to the user's wallet instead of the original payment method; what was paid from the wallet always goes back to it.
 // @Tags bookings
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Booking ID"
+// @Param refund_to query string false "original (default) or wallet"
 // @Success 200 {object} dto.CancelBookingResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /bookings/{id}/cancel [delete]
 func CancelBooking(c *gin.Context) {
@@ -187,104 +311,102 @@ func CancelBooking(c *gin.Context) {
 		return
 	}
 
-	// Check if booking is already cancelled
-	if booking.Status == "cancelled" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking already cancelled"})
+	toWallet, ok := refundToWallet(c)
+	if !ok {
 		return
 	}
 
-	// Start database transaction
-	tx := config.DB.Begin()
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
-		return
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// If no payments, simply cancel the booking
-	if len(booking.Payments) == 0 {
-		booking.Status = "cancelled"
-		if err := tx.Save(&booking).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
-			return
+	result, err := services.CancelBooking(config.DB, &booking, toWallet)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyCancelled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Booking already cancelled"})
+		case errors.Is(err, services.ErrCheckoutCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking: " + err.Error()})
 		}
-		tx.Commit()
-		c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled successfully"})
 		return
 	}
 
-	// Find the latest successful payment
-	var latestPayment *models.Payment
-	for i := len(booking.Payments) - 1; i >= 0; i-- {
-		if booking.Payments[i].Status == "succeeded" {
-			latestPayment = &booking.Payments[i]
-			break
-		}
+	c.JSON(http.StatusOK, cancelBookingResponse(result))
+}
+
+// refundToWallet reads the refund_to query param of a cancellation. It
+// writes the error response and returns false if it is invalid.
+func refundToWallet(c *gin.Context) (toWallet bool, ok bool) {
+	switch c.DefaultQuery("refund_to", "original") {
+	case "original":
+		return false, true
+	case "wallet":
+		return true, true
 	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "refund_to must be original or wallet"})
+	return false, false
+}
 
-	if latestPayment == nil {
-		// No successful payment, just cancel
-		booking.Status = "cancelled"
-		if err := tx.Save(&booking).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
-			return
+// cancelBookingResponse describes the outcome of services.CancelBooking.
+func cancelBookingResponse(result *services.CancelResult) dto.CancelBookingResponse {
+	resp := dto.CancelBookingResponse{
+		Message:        "Booking cancelled successfully",
+		RefundID:       result.RefundID,
+		RefundStatus:   result.RefundStatus,
+		RefundedAmount: result.RefundedAmount,
+	}
+	if result.Policy != nil {
+		resp.Policy = result.Policy.Name
+		resp.RefundPercent = &result.RefundPercent
+		if !result.Refunded {
+			resp.Message = "Booking cancelled, no refund under the cancellation policy"
 		}
-		tx.Commit()
-		c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled successfully"})
-		return
 	}
-
-	// Refund via Stripe - need to get PaymentIntent ID from the session
-	var sessionDetails *stripe.CheckoutSession
-	sessionDetails, err := session.Get(latestPayment.StripeRefID, nil)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payment session"})
-		return
+	if result.Refunded {
+		resp.Message = "Booking cancelled and payment refunded successfully"
+		if result.ToWallet {
+			resp.Message = "Booking cancelled and refunded to your wallet"
+		}
 	}
+	return resp
+}
 
-	if sessionDetails.PaymentIntent == nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Payment intent not found"})
+// MarkBookingPaid godoc
+// @Summary Mark the balance of a booking as paid (Admin only)
+// @Description Record the balance of a partially paid booking as collected at the venue and confirm the booking. An open checkout session for the balance is expired. A receipt is issued for the recorded payment; refunds of it are credited to the customer's wallet.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.Booking
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/{id}/mark-paid [post]
+func MarkBookingPaid(c *gin.Context) {
+	var booking models.Booking
+	if err := config.DB.Preload("Payments").First(&booking, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
 
-	// Create refund
-	refundParams := &stripe.RefundParams{
-		PaymentIntent: stripe.String(sessionDetails.PaymentIntent.ID),
-	}
-	ref, err := stripeRefund.New(refundParams)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process refund: " + err.Error()})
+	if _, err := services.MarkBalancePaid(config.DB, &booking); err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoBalanceDue):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Booking is not partially paid"})
+		case errors.Is(err, services.ErrCheckoutCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark booking paid: " + err.Error()})
+		}
 		return
 	}
 
-	// Update database
-	booking.Status = "cancelled"
-	latestPayment.Status = "refunded"
-
-	if err := tx.Save(&booking).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking status"})
-		return
-	}
-	if err := tx.Save(latestPayment).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
+	var updated models.Booking
+	if err := config.DB.Preload("Field").Preload("Payments").First(&updated, "id = ?", booking.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking"})
 		return
 	}
-
-	tx.Commit()
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Booking cancelled and payment refunded successfully",
-		"refund_id":     ref.ID,
-		"refund_status": ref.Status,
-	})
+	c.JSON(http.StatusOK, updated)
 }
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+	"gorm.io/gorm"
+)
+
+// createBookingSeries handles CreateBooking requests with a recurrence.
+func createBookingSeries(c *gin.Context, userID uuid.UUID, field *models.Field, input *CreateBookingInput) {
+	rec := input.Recurrence
+	occurrences, err := services.Occurrences(input.StartTime, input.EndTime, rec.Frequency, rec.Count, rec.Until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := models.BookingSeries{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FieldID:   field.ID,
+		Frequency: rec.Frequency,
+		Count:     rec.Count,
+		Until:     rec.Until,
+		Status:    "active",
+	}
+
+	holdUntil := time.Now().Add(config.BookingHold)
+	var candidates []models.Booking
+	conflicts := []dto.OccurrenceConflict{}
+	for _, occ := range occurrences {
+		if err := services.CheckOpeningHours(config.DB, field.ID, occ.Start, occ.End); err != nil {
+			if errors.Is(err, services.ErrFieldClosed) || errors.Is(err, services.ErrOutsideOpeningHours) {
+				conflicts = append(conflicts, dto.OccurrenceConflict{StartTime: occ.Start, EndTime: occ.End, Reason: err.Error()})
+				continue
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate opening hours"})
+			return
+		}
+
+		quote, err := services.QuoteBooking(config.DB, field, occ.Start, occ.End)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate booking price"})
+			return
+		}
+
+		booking := models.Booking{
+			ID:        uuid.New(),
+			UserID:    userID,
+			FieldID:   field.ID,
+			StartTime: occ.Start,
+			EndTime:   occ.End,
+			Status:    "pending",
+			Notes:     input.Notes,
+			ExpiresAt: &holdUntil,
+		}
+		services.ApplyQuote(&booking, quote)
+		candidates = append(candidates, booking)
+	}
+
+	_, taken, err := services.CreateBookingSeries(config.DB, &series, candidates)
+	conflicts = append(conflicts, taken...)
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].StartTime.Before(conflicts[j].StartTime) })
+	if err != nil {
+		if errors.Is(err, services.ErrNoFreeOccurrence) {
+			c.JSON(http.StatusConflict, gin.H{"error": "None of the occurrences could be booked", "conflicts": conflicts})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking series"})
+		return
+	}
+
+	if err := config.DB.
+		Preload("Bookings", "status != ?", "cancelled").
+		Preload("Bookings.PriceItems").
+		First(&series, "id = ?", series.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking series"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreateBookingSeriesResponse{
+		Series:    series,
+		Conflicts: conflicts,
+	})
+}
+
+// GetBookingSeries godoc
+// @Summary Get a booking series
+// @Description Get a recurring booking series of the current user with all its occurrences.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Series ID"
+// @Success 200 {object} models.BookingSeries
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /bookings/series/{id} [get]
+func GetBookingSeries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var series models.BookingSeries
+	if err := config.DB.
+		Preload("Bookings", func(db *gorm.DB) *gorm.DB { return db.Order("start_time") }).
+		Preload("Bookings.PriceItems").
+		Preload("Bookings.Payments").
+		First(&series, "id = ? AND user_id = ?", c.Param("id"), userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking series not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// CancelBookingSeries godoc
+// @Summary Cancel a booking series
+// @Description Cancel all upcoming occurrences of a recurring booking. Paid occurrences are refunded, to the wallet with refund_to=wallet. Use DELETE /bookings/{id} to cancel a single occurrence.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Series ID"
+// @Param refund_to query string false "original (default) or wallet"
+// @Success 200 {object} dto.CancelBookingSeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/series/{id} [delete]
+func CancelBookingSeries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	toWallet, ok := refundToWallet(c)
+	if !ok {
+		return
+	}
+
+	var series models.BookingSeries
+	if err := config.DB.First(&series, "id = ? AND user_id = ?", c.Param("id"), userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking series not found"})
+		return
+	}
+
+	if series.Status == "cancelled" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking series already cancelled"})
+		return
+	}
+
+	results, err := services.CancelBookingSeries(config.DB, &series, toWallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking series"})
+		return
+	}
+
+	resp := dto.CancelBookingSeriesResponse{Message: "Booking series cancelled"}
+	for _, r := range results {
+		if r.Err != nil {
+			if resp.Failed == nil {
+				resp.Failed = map[string]string{}
+			}
+			resp.Failed[r.Booking.ID.String()] = r.Err.Error()
+			continue
+		}
+		resp.Cancelled++
+		if r.Result.Refunded {
+			refund := cancelBookingResponse(r.Result)
+			refund.Message = "Booking " + r.Booking.ID.String() + " refunded"
+			resp.Refunds = append(resp.Refunds, refund)
+		}
+	}
+	if len(resp.Failed) > 0 {
+		resp.Message = "Booking series partially cancelled"
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// SplitBooking godoc
+// @Summary Split a booking between participants
+// @Description Invite participants by email to pay a share of a pending, unpaid booking. Give every participant an amount (the owner pays the rest) or none (the total is split equally with the owner). Every share is paid through its own checkout session; the booking is confirmed once all shares are paid before the hold expires, otherwise paid shares are refunded.
+// @Tags bookings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param input body dto.SplitBookingRequest true "Participants"
+// @Success 201 {array} models.BookingShare
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/{id}/shares [post]
+func SplitBooking(c *gin.Context) {
+	var req dto.SplitBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.Preload("User").Preload("Field").
+		First(&booking, "id = ? AND user_id = ?", c.Param("id"), uid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found or not authorized"})
+		return
+	}
+
+	// Bookings created before prices were stored are priced now
+	if err := services.EnsureBookingPrice(config.DB, &booking); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate booking price"})
+		return
+	}
+
+	invites := make([]services.ShareInvite, len(req.Participants))
+	for i, p := range req.Participants {
+		invites[i] = services.ShareInvite{Email: p.Email, Amount: p.Amount}
+	}
+
+	shares, err := services.SplitBooking(config.DB, &booking, invites)
+	switch {
+	case errors.Is(err, services.ErrInvalidShares), errors.Is(err, services.ErrNotSplittable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrBookingSplit):
+		c.JSON(http.StatusConflict, gin.H{"error": "Booking is already split"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to split booking"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, shares)
+}
+
+// GetBookingShares godoc
+// @Summary Get the shares of a booking
+// @Description List the shares of a booking of the current user, cancelled and expired ones included.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {array} models.BookingShare
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/{id}/shares [get]
+func GetBookingShares(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.Select("id").First(&booking, "id = ? AND user_id = ?", c.Param("id"), uid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found or not authorized"})
+		return
+	}
+
+	var shares []models.BookingShare
+	if err := config.DB.Where("booking_id = ?", booking.ID).
+		Order("owner DESC, created_at").Find(&shares).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking shares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// CancelBookingSplit godoc
+// @Summary Cancel the split of a booking
+// @Description Cancel the shares of a booking of the current user so it can be paid in one checkout again. Only possible while no share is paid or being paid.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/{id}/shares [delete]
+func CancelBookingSplit(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.Select("id").First(&booking, "id = ? AND user_id = ?", c.Param("id"), uid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found or not authorized"})
+		return
+	}
+
+	if err := services.CancelSplit(config.DB, booking.ID); err != nil {
+		if errors.Is(err, services.ErrSplitStarted) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel split"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking split cancelled"})
+}
+
+// CoverBookingShares godoc
+// @Summary Pay the remaining shares of a booking
+// @Description Open one checkout session with which the booking owner pays every share nobody has paid or started paying yet, e.g. for participants who dropped out.
+// @Tags bookings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param input body dto.ShareCheckoutRequest false "Payment provider"
+// @Success 200 {object} dto.CreateCheckoutSessionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bookings/{id}/shares/cover [post]
+func CoverBookingShares(c *gin.Context) {
+	var req dto.ShareCheckoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	provider, err := payments.Selectable(req.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.Preload("Field").
+		First(&booking, "id = ? AND user_id = ?", c.Param("id"), uid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found or not authorized"})
+		return
+	}
+
+	// Shares with an open checkout are left to whoever is paying them
+	var shares []models.BookingShare
+	if err := config.DB.Where("booking_id = ? AND status = ?", booking.ID, "invited").
+		Where("id NOT IN (?)", config.DB.Model(&models.Payment{}).Select("share_id").
+			Where("booking_id = ? AND share_id IS NOT NULL AND status IN ?", booking.ID, []string{"pending", "succeeded"})).
+		Order("owner DESC, created_at").
+		Find(&shares).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking shares"})
+		return
+	}
+	if len(shares) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No unpaid shares to cover"})
+		return
+	}
+
+	openShareCheckout(c, provider, &booking, shares, uid)
+}
+
+// GetMyShares godoc
+// @Summary Get my booking shares
+// @Description List the shares of split bookings the current user was invited to by email, with their booking and field.
+// @Tags bookings
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.BookingShare
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shares/me [get]
+func GetMyShares(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "email").First(&user, "id = ?", uid).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var shares []models.BookingShare
+	if err := config.DB.Preload("Booking.Field").
+		Where("email = ? AND owner = ?", strings.ToLower(user.Email), false).
+		Order("created_at DESC").
+		Find(&shares).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// CreateShareCheckout godoc
+// @Summary Pay a booking share
+// @Description Open a checkout session paying one share of a split booking. Allowed for the participant the share was sent to and for the booking owner.
+// @Tags bookings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Share ID"
+// @Param input body dto.ShareCheckoutRequest false "Payment provider"
+// @Success 200 {object} dto.CreateCheckoutSessionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shares/{id}/checkout [post]
+func CreateShareCheckout(c *gin.Context) {
+	var req dto.ShareCheckoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	provider, err := payments.Selectable(req.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "email").First(&user, "id = ?", uid).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var share models.BookingShare
+	if err := config.DB.Preload("Booking.Field").First(&share, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found or not authorized"})
+		return
+	}
+	if share.Email != strings.ToLower(user.Email) && share.Booking.UserID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found or not authorized"})
+		return
+	}
+
+	booking := share.Booking
+	share.Booking = nil
+	openShareCheckout(c, provider, booking, []models.BookingShare{share}, uid)
+}
+
+// openShareCheckout opens the checkout session paying shares of a booking
+// and writes the response.
+func openShareCheckout(c *gin.Context, provider payments.Provider, booking *models.Booking, shares []models.BookingShare, payerID uuid.UUID) {
+	s, amount, err := services.OpenShareCheckout(config.DB, provider, booking, shares, payerID)
+	switch {
+	case errors.Is(err, services.ErrShareNotPayable), errors.Is(err, services.ErrBookingNotPayable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		checkoutError(c, provider, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateCheckoutSessionResponse{
+		SessionID:  s.ID,
+		SessionURL: s.URL,
+		Amount:     amount,
+		Currency:   booking.Currency,
+	})
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetFieldCancellationPolicy godoc
+// @Summary Get a field's cancellation policy
+// @Description Get the cancellation policy applied to bookings of a field: the field's own policy, or the global policy when it has none. Without any policy cancellations are refunded in full.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Success 200 {object} models.CancellationPolicy
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/{id}/cancellation-policy [get]
+func GetFieldCancellationPolicy(c *gin.Context) {
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	policy, err := services.EffectiveCancellationPolicy(config.DB, field.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cancellation policy"})
+		return
+	}
+	if policy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No cancellation policy, cancellations are refunded in full"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetFieldCancellationPolicy godoc
+// @Summary Set a field's cancellation policy (Admin only)
+// @Description Create or replace the cancellation policy of a field. It takes precedence over the global policy.
+// @Tags fields
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param input body dto.CancellationPolicyRequest true "Cancellation policy"
+// @Success 200 {object} models.CancellationPolicy
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/cancellation-policy [put]
+func SetFieldCancellationPolicy(c *gin.Context) {
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	saveCancellationPolicy(c, &field.ID)
+}
+
+// DeleteFieldCancellationPolicy godoc
+// @Summary Delete a field's cancellation policy (Admin only)
+// @Description Delete the cancellation policy of a field; the global policy applies again.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/cancellation-policy [delete]
+func DeleteFieldCancellationPolicy(c *gin.Context) {
+	result := config.DB.Where("field_id = ?", c.Param("id")).Delete(&models.CancellationPolicy{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cancellation policy not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation policy deleted successfully"})
+}
+
+// GetGlobalCancellationPolicy godoc
+// @Summary Get the global cancellation policy
+// @Description Get the cancellation policy applied to fields without their own policy.
+// @Tags cancellation-policy
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.CancellationPolicy
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /cancellation-policy [get]
+func GetGlobalCancellationPolicy(c *gin.Context) {
+	var policy models.CancellationPolicy
+	if err := config.DB.Where("field_id IS NULL").First(&policy).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No global cancellation policy, cancellations are refunded in full"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetGlobalCancellationPolicy godoc
+// @Summary Set the global cancellation policy (Admin only)
+// @Description Create or replace the cancellation policy applied to fields without their own policy.
+// @Tags cancellation-policy
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.CancellationPolicyRequest true "Cancellation policy"
+// @Success 200 {object} models.CancellationPolicy
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /cancellation-policy [put]
+func SetGlobalCancellationPolicy(c *gin.Context) {
+	saveCancellationPolicy(c, nil)
+}
+
+// DeleteGlobalCancellationPolicy godoc
+// @Summary Delete the global cancellation policy (Admin only)
+// @Description Delete the global cancellation policy; fields without their own policy refund cancellations in full again.
+// @Tags cancellation-policy
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /cancellation-policy [delete]
+func DeleteGlobalCancellationPolicy(c *gin.Context) {
+	result := config.DB.Where("field_id IS NULL").Delete(&models.CancellationPolicy{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cancellation policy not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation policy deleted successfully"})
+}
+
+// saveCancellationPolicy creates or replaces the policy of a field, or the
+// global policy when fieldID is nil.
+func saveCancellationPolicy(c *gin.Context, fieldID *uuid.UUID) {
+	var req dto.CancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := models.CancellationPolicy{FieldID: fieldID}
+	policy.Name = req.Name
+	policy.Tiers = make([]models.CancellationTier, 0, len(req.Tiers))
+	for _, t := range req.Tiers {
+		policy.Tiers = append(policy.Tiers, models.CancellationTier{
+			MinHoursBefore: *t.MinHoursBefore,
+			RefundPercent:  *t.RefundPercent,
+		})
+	}
+	if err := services.ValidateCancellationPolicy(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.SaveCancellationPolicy(config.DB, &policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save cancellation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetCoupons godoc
+// @Summary List promo codes (Admin only)
+// @Description List all promo codes, newest first, with how many checkouts used them.
+// @Tags coupons
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.CouponResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coupons [get]
+func GetCoupons(c *gin.Context) {
+	var coupons []models.Coupon
+	if err := config.DB.Order("created_at DESC").Find(&coupons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch coupons"})
+		return
+	}
+
+	resp := make([]dto.CouponResponse, 0, len(coupons))
+	for _, coupon := range coupons {
+		uses, err := services.CouponUses(config.DB, coupon.ID, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count coupon uses"})
+			return
+		}
+		resp = append(resp, dto.CouponResponse{Coupon: coupon, Uses: uses})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetCoupon godoc
+// @Summary Get a promo code (Admin only)
+// @Description Get a promo code with how many checkouts used it.
+// @Tags coupons
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} dto.CouponResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coupons/{id} [get]
+func GetCoupon(c *gin.Context) {
+	var coupon models.Coupon
+	if err := config.DB.First(&coupon, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+		return
+	}
+
+	uses, err := services.CouponUses(config.DB, coupon.ID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count coupon uses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CouponResponse{Coupon: coupon, Uses: uses})
+}
+
+// CreateCoupon godoc
+// @Summary Create a promo code (Admin only)
+// @Description Create a percentage or fixed-amount promo code with an optional validity window, global and per-user usage limits and field restriction. Codes are case-insensitive and stored upper case.
+// @Tags coupons
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.CouponRequest true "Promo code"
+// @Success 201 {object} models.Coupon
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coupons [post]
+func CreateCoupon(c *gin.Context) {
+	var req dto.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var coupon models.Coupon
+	if !applyCouponRequest(c, &coupon, &req) {
+		return
+	}
+
+	if err := config.DB.Create(&coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create coupon"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a promo code (Admin only)
+// @Description Replace all attributes of a promo code. Checkouts that already used it keep their discount.
+// @Tags coupons
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Param input body dto.CouponRequest true "Promo code"
+// @Success 200 {object} models.Coupon
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coupons/{id} [put]
+func UpdateCoupon(c *gin.Context) {
+	var coupon models.Coupon
+	if err := config.DB.First(&coupon, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+		return
+	}
+
+	var req dto.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !applyCouponRequest(c, &coupon, &req) {
+		return
+	}
+
+	if err := config.DB.Save(&coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update coupon"})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a promo code (Admin only)
+// @Description Delete a promo code. Payments that used it keep the code and discount; to stop new uses but keep the statistics, set active to false instead.
+// @Tags coupons
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coupons/{id} [delete]
+func DeleteCoupon(c *gin.Context) {
+	result := config.DB.Where("id = ?", c.Param("id")).Delete(&models.Coupon{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete coupon"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Coupon deleted successfully"})
+}
+
+// applyCouponRequest copies a request onto coupon and validates it. On
+// failure it responds and returns false.
+func applyCouponRequest(c *gin.Context, coupon *models.Coupon, req *dto.CouponRequest) bool {
+	coupon.Code = services.NormalizeCouponCode(req.Code)
+	coupon.Description = req.Description
+	coupon.DiscountType = req.DiscountType
+	coupon.DiscountValue = req.DiscountValue
+	coupon.Currency = config.Currency
+	coupon.ValidFrom = req.ValidFrom
+	coupon.ValidUntil = req.ValidUntil
+	coupon.MaxUses = req.MaxUses
+	coupon.MaxUsesPerUser = req.MaxUsesPerUser
+	coupon.Active = req.Active == nil || *req.Active
+
+	coupon.FieldIDs = make([]uuid.UUID, 0, len(req.FieldIDs))
+	for _, id := range req.FieldIDs {
+		coupon.FieldIDs = append(coupon.FieldIDs, uuid.MustParse(id))
+	}
+	if len(coupon.FieldIDs) > 0 {
+		var found int64
+		if err := config.DB.Model(&models.Field{}).Where("id IN ?", coupon.FieldIDs).Count(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check fields"})
+			return false
+		}
+		if found != int64(len(coupon.FieldIDs)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown field in field_ids"})
+			return false
+		}
+	}
+
+	if err := services.ValidateCoupon(coupon); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	var taken int64
+	if err := config.DB.Model(&models.Coupon{}).
+		Where("code = ? AND id <> ?", coupon.Code, coupon.ID).
+		Count(&taken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check coupon code"})
+		return false
+	}
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Coupon code already exists"})
+		return false
+	}
+	return true
+}
@@ -2,13 +2,20 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
 	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+	"gorm.io/gorm"
 )
 
+const maxAvailabilityDays = 31
+
 // GetFields godoc
 // @Summary Get all fields
 // @Description Get a list of all fields with optional filtering by location and price range
@@ -50,7 +57,7 @@ func GetFields(c *gin.Context) {
 
 // GetFieldByID godoc
 // @Summary Get a field by ID
-// @Description Get a single field by its ID
+// @Description Get a single field by its ID, including its opening hours and upcoming closures
 // @Tags fields
 // @Produce json
 // @Param id path string true "Field ID"
@@ -61,7 +68,10 @@ func GetFieldByID(c *gin.Context) {
 	id := c.Param("id")
 
 	var field models.Field
-	if err := config.DB.First(&field, "id = ?", id).Error; err != nil {
+	if err := config.DB.
+		Preload("OpeningHours", func(db *gorm.DB) *gorm.DB { return db.Order("weekday, open_time") }).
+		Preload("Closures", "date >= ?", time.Now().In(config.Location).Format("2006-01-02")).
+		First(&field, "id = ?", id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
 		return
 	}
@@ -69,6 +79,72 @@ func GetFieldByID(c *gin.Context) {
 	c.JSON(http.StatusOK, field)
 }
 
+// GetFieldAvailability godoc
+// @Summary Get field availability
+// @Description Get the free and booked time slots of a field for one day or a range of days (max 31). Cancelled bookings are ignored.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string false "Last day, inclusive (YYYY-MM-DD). Defaults to date"
+// @Param slot_minutes query int false "Slot length in minutes, must divide 1440 (default 60)"
+// @Success 200 {object} dto.FieldAvailabilityResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/{id}/availability [get]
+func GetFieldAvailability(c *gin.Context) {
+	id := c.Param("id")
+
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	firstDay, err := time.ParseInLocation("2006-01-02", c.Query("date"), config.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing date, expected YYYY-MM-DD"})
+		return
+	}
+
+	lastDay := firstDay
+	if endDate := c.Query("end_date"); endDate != "" {
+		lastDay, err = time.ParseInLocation("2006-01-02", endDate, config.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if lastDay.Before(firstDay) || lastDay.Sub(firstDay) >= maxAvailabilityDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be within 31 days after date"})
+		return
+	}
+
+	slotMinutes := 60
+	if v := c.Query("slot_minutes"); v != "" {
+		slotMinutes, err = strconv.Atoi(v)
+		if err != nil || slotMinutes < 15 || 1440%slotMinutes != 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slot_minutes must be at least 15 and divide 1440"})
+			return
+		}
+	}
+
+	days, err := services.FieldAvailability(config.DB, field.ID, firstDay, lastDay, time.Duration(slotMinutes)*time.Minute)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FieldAvailabilityResponse{
+		FieldID:     field.ID.String(),
+		Timezone:    config.Location.String(),
+		SlotMinutes: slotMinutes,
+		Days:        days,
+	})
+}
+
 // CreateField godoc
 // @Summary Create a new field (Admin only)
 // @Description Create a new field. Requires admin privileges.
@@ -98,9 +174,10 @@ func CreateField(c *gin.Context) {
 	}
 
 	var input struct {
-		Name     string  `json:"name" binding:"required"`
-		Location string  `json:"location" binding:"required"`
-		Price    float64 `json:"price" binding:"required"`
+		Name           string  `json:"name" binding:"required"`
+		Location       string  `json:"location" binding:"required"`
+		Price          float64 `json:"price" binding:"required"`
+		DepositPercent float64 `json:"deposit_percent" binding:"gte=0,lt=100"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -113,6 +190,8 @@ func CreateField(c *gin.Context) {
 		Name:     input.Name,
 		Location: input.Location,
 		Price:    input.Price,
+
+		DepositPercent: input.DepositPercent,
 	}
 
 	if err := config.DB.Create(&field).Error; err != nil {
@@ -153,9 +232,10 @@ func UpdateField(c *gin.Context) {
 	}
 
 	var input struct {
-		Name     string  `json:"name"`
-		Location string  `json:"location"`
-		Price    float64 `json:"price"`
+		Name           string   `json:"name"`
+		Location       string   `json:"location"`
+		Price          float64  `json:"price"`
+		DepositPercent *float64 `json:"deposit_percent" binding:"omitempty,gte=0,lt=100"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -172,6 +252,9 @@ func UpdateField(c *gin.Context) {
 	if input.Price != 0 {
 		field.Price = input.Price
 	}
+	if input.DepositPercent != nil {
+		field.DepositPercent = *input.DepositPercent
+	}
 
 	if err := config.DB.Save(&field).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -200,7 +283,22 @@ func DeleteField(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.Field{}, "id = ?", id).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("field_id = ?", id).Delete(&models.FieldOpeningHour{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("field_id = ?", id).Delete(&models.FieldClosure{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("field_id = ?", id).Delete(&models.PricingRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("field_id = ?", id).Delete(&models.CancellationPolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Field{}, "id = ?", id).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
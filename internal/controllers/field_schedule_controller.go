@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+	"gorm.io/gorm"
+)
+
+// UpdateOpeningHours godoc
+// @Summary Replace a field's weekly opening hours (Admin only)
+// @Description Replace the weekly opening-hours schedule of a field. Each weekday may have several intervals. An empty list makes the field open around the clock.
+// @Tags fields
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param input body dto.UpdateOpeningHoursRequest true "Weekly schedule"
+// @Success 200 {array} models.FieldOpeningHour
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/opening-hours [put]
+func UpdateOpeningHours(c *gin.Context) {
+	id := c.Param("id")
+
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	var req dto.UpdateOpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hours := make([]models.FieldOpeningHour, 0, len(req.OpeningHours))
+	for _, h := range req.OpeningHours {
+		hours = append(hours, models.FieldOpeningHour{
+			FieldID:   field.ID,
+			Weekday:   *h.Weekday,
+			OpenTime:  h.OpenTime,
+			CloseTime: h.CloseTime,
+		})
+	}
+
+	if err := services.ValidateOpeningHours(hours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Where("field_id = ?", field.ID).Delete(&models.FieldOpeningHour{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update opening hours"})
+		return
+	}
+	if len(hours) > 0 {
+		if err := tx.Create(&hours).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update opening hours"})
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update opening hours"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hours)
+}
+
+// CreateFieldClosure godoc
+// @Summary Close a field on a date (Admin only)
+// @Description Mark a whole day as closed (holiday, maintenance). No bookings are accepted on that date.
+// @Tags fields
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param input body dto.CreateClosureRequest true "Closure data"
+// @Success 201 {object} models.FieldClosure
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/closures [post]
+func CreateFieldClosure(c *gin.Context) {
+	id := c.Param("id")
+
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	var req dto.CreateClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.ParseInLocation("2006-01-02", req.Date, config.Location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	closure := models.FieldClosure{
+		FieldID: field.ID,
+		Date:    req.Date,
+		Reason:  req.Reason,
+	}
+	if err := config.DB.Create(&closure).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Field is already closed on this date"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create closure"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, closure)
+}
+
+// DeleteFieldClosure godoc
+// @Summary Remove a field closure (Admin only)
+// @Description Reopen a field on a previously closed date.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param closure_id path string true "Closure ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/closures/{closure_id} [delete]
+func DeleteFieldClosure(c *gin.Context) {
+	result := config.DB.Where("id = ? AND field_id = ?", c.Param("closure_id"), c.Param("id")).
+		Delete(&models.FieldClosure{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete closure"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Closure not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted successfully"})
+}
@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// VerifyMFA godoc
+// @Summary Complete login with a second factor
+// @Description Exchange the MFA challenge token returned by login, together with a code from the authenticator app or a recovery code, for an access and refresh token. The challenge token can be used once, so after a wrong code the login has to be started again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body dto.MFAVerifyRequest true "Challenge token and code"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa/verify [post]
+func VerifyMFA(c *gin.Context) {
+	var input dto.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := services.CompleteMFAChallenge(config.DB, input.MFAToken, input.Code)
+	switch {
+	case errors.Is(err, services.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please log in again"})
+		return
+	case errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code, please log in again"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+
+	if tokens, ok := issueTokens(c, user, true); ok {
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+// GetMFAStatus godoc
+// @Summary Get two-factor authentication status
+// @Description Whether the current user has two-factor authentication enabled, whether their role requires it and how many recovery codes are left.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.MFAStatusResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa [get]
+func GetMFAStatus(c *gin.Context) {
+	user, ok := mfaUser(c)
+	if !ok {
+		return
+	}
+
+	left, err := services.RecoveryCodesLeft(config.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MFAStatusResponse{
+		Enabled:           services.MFAEnabled(user),
+		EnabledAt:         user.TOTPEnabledAt,
+		Required:          config.MFARequired(user.Role),
+		RecoveryCodesLeft: left,
+	})
+}
+
+// SetupMFA godoc
+// @Summary Set up two-factor authentication
+// @Description Generate a TOTP secret for the current user to add to an authenticator app (e.g. by showing otpauth_url as a QR code). Two-factor authentication is turned on with /auth/mfa/enable. Setting up again replaces a secret that was not enabled yet. For roles that require two-factor authentication an enrollment token is also emailed to the user (enrollment_token_sent), as their login so far only proves the password.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.MFASetupResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa/setup [post]
+func SetupMFA(c *gin.Context) {
+	user, ok := mfaUser(c)
+	if !ok {
+		return
+	}
+
+	secret, otpauthURL, err := services.SetupMFA(config.DB, user)
+	if err != nil {
+		if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MFASetupResponse{
+		Secret:              secret,
+		OTPAuthURL:          otpauthURL,
+		EnrollmentTokenSent: config.MFARequired(user.Role),
+	})
+}
+
+// EnableMFA godoc
+// @Summary Enable two-factor authentication
+// @Description Turn on two-factor authentication with a code from the authenticator app set up with /auth/mfa/setup and, for roles that require it, the enrollment token emailed on setup. Returns the recovery codes, which are shown only once, and new tokens issued with the second factor; other sessions have to log in again.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.MFAEnableRequest true "Code from the authenticator app and enrollment token"
+// @Success 200 {object} dto.MFAEnableResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa/enable [post]
+func EnableMFA(c *gin.Context) {
+	var input dto.MFAEnableRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := mfaUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := services.EnableMFA(config.DB, user, input.Code, input.EnrollmentToken)
+	switch {
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrMFANotSetUp), errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrMFAEnrollmentTokenRequired), errors.Is(err, services.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	tokens, ok := issueTokens(c, user, true)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dto.MFAEnableResponse{RecoveryCodes: codes, LoginResponse: *tokens})
+}
+
+// DisableMFA godoc
+// @Summary Disable two-factor authentication
+// @Description Turn off two-factor authentication of the current user with a code from the authenticator app or a recovery code. Not allowed for roles that require it.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa/disable [post]
+func DisableMFA(c *gin.Context) {
+	var input dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := mfaUser(c)
+	if !ok {
+		return
+	}
+
+	err := services.DisableMFA(config.DB, user, input.Code)
+	switch {
+	case errors.Is(err, services.ErrMFARequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrMFANotEnabled), errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user, confirmed with a code from the authenticator app or a recovery code. The new codes are shown only once.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/mfa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := mfaUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(config.DB, user, input.Code)
+	switch {
+	case errors.Is(err, services.ErrMFANotEnabled), errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// mfaUser loads the current user. On failure it writes the error response.
+func mfaUser(c *gin.Context) (*models.User, bool) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", uid).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	return &user, true
+}
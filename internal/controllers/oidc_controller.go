@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/oidc"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// OIDCLogin godoc
+// @Summary Log in with an identity provider
+// @Description Start a login with an OpenID Connect provider (e.g. google) using the authorization code flow with PKCE. Redirects the browser to the provider's sign-in page, which redirects back to the callback. Sets an HttpOnly oidc_state cookie, valid for 10 minutes, so that only this browser can complete the login.
+// @Tags auth
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 302 "Redirect to the provider"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Router /auth/oidc/{provider} [get]
+func OIDCLogin(c *gin.Context) {
+	provider, err := oidc.Get(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	authURL, state, err := services.StartOIDCLogin(c.Request.Context(), config.DB, provider)
+	if err != nil {
+		log.Printf("⚠️ Failed to start %s login: %v", provider.Name, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach login provider"})
+		return
+	}
+
+	// The callback only completes the login in this browser
+	setOIDCStateCookie(c, c.Request.URL.Path, state, int(services.OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Identity provider login callback
+// @Description The provider redirects here after sign-in. The state must match the oidc_state cookie set when the login was started in this browser. The user is found by their provider identity or verified email (linking the identity to the existing account), or a new account is created. Responds like a password login (tokens, or an MFA challenge for users with two-factor authentication), or redirects to OIDC_LOGIN_REDIRECT_URL with them in the URL fragment when it is set.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param code query string true "Authorization code"
+// @Param state query string true "Login state"
+// @Success 200 {object} dto.LoginResponse
+// @Success 302 "Redirect to the frontend with the tokens"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Router /auth/oidc/{provider}/callback [get]
+func OIDCCallback(c *gin.Context) {
+	provider, err := oidc.Get(c.Param("provider"))
+	if err != nil {
+		oidcLoginFailed(c, http.StatusNotFound, "Unknown login provider")
+		return
+	}
+	if msg := c.Query("error"); msg != "" {
+		if desc := c.Query("error_description"); desc != "" {
+			msg += ": " + desc
+		}
+		oidcLoginFailed(c, http.StatusBadRequest, "Login cancelled or denied by provider ("+msg+")")
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		oidcLoginFailed(c, http.StatusBadRequest, "code and state are required")
+		return
+	}
+
+	browserState, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, path.Dir(c.Request.URL.Path), "", -1)
+
+	user, err := services.FinishOIDCLogin(c.Request.Context(), config.DB, provider, state, browserState, code)
+	switch {
+	case errors.Is(err, services.ErrInvalidOIDCState):
+		oidcLoginFailed(c, http.StatusBadRequest, "Login expired or already completed, please try again")
+		return
+	case errors.Is(err, services.ErrOIDCStateMismatch):
+		oidcLoginFailed(c, http.StatusBadRequest, "Login was not started in this browser, please try again")
+		return
+	case errors.Is(err, oidc.ErrInvalidIDToken):
+		log.Printf("⚠️ Rejected %s ID token: %v", provider.Name, err)
+		oidcLoginFailed(c, http.StatusUnauthorized, "Invalid ID token")
+		return
+	case errors.Is(err, services.ErrOIDCEmailNotVerified):
+		oidcLoginFailed(c, http.StatusForbidden, "Your email address is not verified by the login provider")
+		return
+	case err != nil:
+		log.Printf("⚠️ Failed to finish %s login: %v", provider.Name, err)
+		oidcLoginFailed(c, http.StatusBadGateway, "Failed to complete login with provider")
+		return
+	}
+
+	res, ok := signIn(c, user)
+	if !ok {
+		return
+	}
+	if config.OIDCLoginRedirectURL == "" {
+		c.JSON(http.StatusOK, res)
+		return
+	}
+	var fragment url.Values
+	switch res := res.(type) {
+	case *dto.LoginResponse:
+		fragment = url.Values{
+			"access_token":  {res.AccessToken},
+			"expires_in":    {strconv.FormatInt(res.ExpiresIn, 10)},
+			"refresh_token": {res.RefreshToken},
+		}
+	case *dto.MFAChallengeResponse:
+		fragment = url.Values{
+			"mfa_required": {"true"},
+			"mfa_token":    {res.MFAToken},
+			"expires_in":   {strconv.FormatInt(res.ExpiresIn, 10)},
+		}
+	}
+	c.Redirect(http.StatusFound, config.OIDCLoginRedirectURL+"#"+fragment.Encode())
+}
+
+// oidcStateCookie holds the state of the login started in the browser.
+const oidcStateCookie = "oidc_state"
+
+// setOIDCStateCookie sets the login state cookie for the provider's login
+// path, which covers its callback, or deletes it with a negative maxAge.
+func setOIDCStateCookie(c *gin.Context, cookiePath, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     cookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		// Sent on the provider's top-level redirect back to the callback
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oidcLoginFailed reports a failed login to the frontend page when one is
+// configured, since the callback is opened by the browser, and as JSON
+// otherwise.
+func oidcLoginFailed(c *gin.Context, status int, msg string) {
+	if config.OIDCLoginRedirectURL == "" {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	c.Redirect(http.StatusFound, config.OIDCLoginRedirectURL+"#"+url.Values{"error": {msg}}.Encode())
+}
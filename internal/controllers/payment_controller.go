@@ -1,24 +1,28 @@
 package controllers
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
 	"github.com/qullDev/BookMyField/internal/dto"
 	"github.com/qullDev/BookMyField/internal/models"
-	"github.com/stripe/stripe-go/v76"
-	"github.com/stripe/stripe-go/v76/checkout/session"
-	"github.com/stripe/stripe-go/v76/webhook"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/receipts"
+	"github.com/qullDev/BookMyField/internal/services"
+	"gorm.io/gorm"
 )
 
 // CreateCheckoutSession godoc
 // @Summary Create a checkout session
-// @Description Create a new checkout session for a booking payment.
+// @Description Create a new checkout session for a booking payment with the configured payment provider. The booking's computed total is charged in the configured currency. Pass series_id instead of booking_id to pay all pending occurrences of a recurring booking in one session. Set provider to pick the gateway, e.g. midtrans for bank transfer, QRIS and e-wallets in IDR. An optional promo_code takes its discount off the line items; the discount is recorded on the payments. With use_wallet the wallet balance pays for the bookings, up to wallet_amount when given; if it covers everything the bookings are confirmed at once without a checkout session, otherwise the rest is charged through the provider. If the booking's field takes a deposit only the deposit is charged (unless pay_in_full) and the paid booking becomes partially_paid; a checkout for a partially paid booking charges the balance.
 // @Tags payments
 // @Security BearerAuth
 // @Accept json
@@ -27,6 +31,7 @@ import (
 // @Success 200 {object} dto.CreateCheckoutSessionResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /payments/create-checkout-session [post]
@@ -43,6 +48,17 @@ func CreateCheckoutSession(c *gin.Context) {
 		return
 	}
 
+	provider, err := payments.Selectable(req.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.BookingID == "" {
+		createSeriesCheckoutSession(c, provider, &req, userID)
+		return
+	}
+
 	var booking models.Booking
 	if err := config.DB.Preload("Field").
 		Where("id = ? AND user_id = ?", req.BookingID, userID).
@@ -51,6 +67,11 @@ func CreateCheckoutSession(c *gin.Context) {
 		return
 	}
 
+	if booking.Status == "partially_paid" {
+		createBalanceCheckoutSession(c, provider, &booking, &req)
+		return
+	}
+
 	var existingPayment models.Payment
 	if err := config.DB.Where("booking_id = ? AND status IN (?)", req.BookingID, []string{"pending", "succeeded"}).First(&existingPayment).Error; err == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment already exists for this booking"})
@@ -62,56 +83,304 @@ func CreateCheckoutSession(c *gin.Context) {
 		return
 	}
 
-	params := &stripe.CheckoutSessionParams{
-		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
-		Mode:               stripe.String("payment"),
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency: stripe.String("usd"), // Use USD for Stripe compatibility
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String(booking.Field.Name + " - Field Booking"),
-					},
-					UnitAmount: stripe.Int64(100), // Fixed $1.00 USD for testing (minimum amount)
-				},
-				Quantity: stripe.Int64(1),
-			},
-		},
-		SuccessURL: stripe.String("https://bookmyfield-production.up.railway.app/success?session_id={CHECKOUT_SESSION_ID}"),
-		CancelURL:  stripe.String("https://bookmyfield-production.up.railway.app/cancel"),
-		Metadata: map[string]string{
-			"booking_id": booking.ID.String(),
-		},
-	}
-
-	s, err := session.New(params)
+	if booking.ExpiresAt != nil && booking.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking hold has expired"})
+		return
+	}
+
+	split, err := services.IsSplit(config.DB, booking.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check booking shares"})
+		return
+	}
+	if split {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrBookingSplit.Error()})
 		return
 	}
 
-	payment := models.Payment{
-		ID:          uuid.New(),
-		BookingID:   booking.ID,
-		Amount:      booking.Field.Price,
-		Currency:    "idr",
-		Status:      "pending",
-		StripeRefID: s.ID, //  session ID for webhook matching
+	// Bookings created before prices were stored are priced now
+	if err := services.EnsureBookingPrice(config.DB, &booking); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate booking price"})
+		return
 	}
-	if err := config.DB.Create(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment record"})
+
+	// One line item per price breakdown line
+	var items []services.CheckoutItem
+	for _, item := range booking.PriceItems {
+		items = append(items, services.CheckoutItem{Name: item.Description, Amount: item.Amount})
+	}
+
+	openCheckout(c, provider, []models.Booking{booking}, items, &req, map[string]string{
+		"booking_id": booking.ID.String(),
+	}, depositPurpose(&booking, &req))
+}
+
+// depositPurpose is the purpose of the checkout of a pending booking (with
+// Field loaded): its deposit, unless its field takes none or the customer
+// pays in full.
+func depositPurpose(booking *models.Booking, req *dto.CreateCheckoutSessionRequest) string {
+	if req.PayInFull || booking.Field.DepositPercent <= 0 || booking.Field.DepositPercent >= 100 {
+		return ""
+	}
+	return services.PurposeDeposit
+}
+
+// createBalanceCheckoutSession creates the checkout session paying what is
+// left of a booking (with Field loaded) whose deposit has been paid.
+func createBalanceCheckoutSession(c *gin.Context, provider payments.Provider, booking *models.Booking, req *dto.CreateCheckoutSessionRequest) {
+	if req.PromoCode != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo codes can only be applied to the deposit"})
+		return
+	}
+
+	if err := config.DB.Where("booking_id = ?", booking.ID).Order("created_at").Find(&booking.Payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking payments"})
+		return
+	}
+	for _, p := range booking.Payments {
+		if p.Status == "pending" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrBalanceInProgress.Error()})
+			return
+		}
+	}
+	due := services.BalanceDue(booking)
+	if due <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrNoBalanceDue.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.CreateCheckoutSessionResponse{
-		SessionID:  s.ID,
-		SessionURL: s.URL,
+	name := fmt.Sprintf("%s - %s (balance)", booking.Field.Name, booking.StartTime.In(config.Location).Format("Mon 02 Jan 2006 15:04"))
+	openCheckout(c, provider, []models.Booking{*booking}, []services.CheckoutItem{{Name: name, Amount: due}}, req, map[string]string{
+		"booking_id": booking.ID.String(),
+		"purpose":    services.PurposeBalance,
+	}, services.PurposeBalance)
+}
+
+// createSeriesCheckoutSession creates one checkout session paying for every
+// pending, unpaid occurrence of a booking series. A payment record is stored
+// per booking, all sharing the session ID.
+func createSeriesCheckoutSession(c *gin.Context, provider payments.Provider, req *dto.CreateCheckoutSessionRequest, userID interface{}) {
+	var series models.BookingSeries
+	if err := config.DB.Where("id = ? AND user_id = ?", req.SeriesID, userID).First(&series).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking series not found or not authorized"})
+		return
+	}
+
+	var bookings []models.Booking
+	if err := config.DB.Preload("Field").Preload("PriceItems").
+		Where("series_id = ? AND status = ?", series.ID, "pending").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now().UTC()).
+		Where("id NOT IN (?)", config.DB.Model(&models.Payment{}).Select("booking_id").Where("status IN (?)", []string{"pending", "succeeded"})).
+		Where("id NOT IN (?)", config.DB.Model(&models.BookingShare{}).Select("booking_id").Where("status IN (?)", []string{"invited", "paid"})).
+		Order("start_time").
+		Find(&bookings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch series bookings"})
+		return
+	}
+
+	if len(bookings) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending bookings to pay in this series"})
+		return
+	}
+
+	// One line item per occurrence
+	var items []services.CheckoutItem
+	for i := range bookings {
+		b := &bookings[i]
+		if err := services.EnsureBookingPrice(config.DB, b); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate booking price"})
+			return
+		}
+		if b.Currency != bookings[0].Currency {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Series bookings are priced in different currencies"})
+			return
+		}
+		name := fmt.Sprintf("%s - %s", b.Field.Name, b.StartTime.In(config.Location).Format("Mon 02 Jan 2006 15:04"))
+		items = append(items, services.CheckoutItem{Name: name, Amount: b.TotalPrice})
+	}
+
+	openCheckout(c, provider, bookings, items, req, map[string]string{
+		"series_id": series.ID.String(),
+	}, depositPurpose(&bookings[0], req))
+}
+
+// openCheckout applies the promo code and the wallet, if requested, records
+// pending payments per booking and then opens a checkout session for the rest
+// of items, the payments all sharing the session ID for webhook matching. The discount is
+// taken off the line items and recorded on the payments. A booking paid
+// partly from the wallet gets a wallet payment next to the provider payment;
+// bookings paid entirely from the wallet are confirmed right away. purpose
+// is services.PurposeDeposit to charge only the deposit of the bookings'
+// field, services.PurposeBalance to charge what is left after it (Payments
+// loaded) or empty to charge the full price.
+func openCheckout(c *gin.Context, provider payments.Provider, bookings []models.Booking, items []services.CheckoutItem, req *dto.CreateCheckoutSessionRequest, metadata map[string]string, purpose string) {
+	currency := bookings[0].Currency
+	var (
+		s          *payments.CheckoutSession
+		coupon     *models.Coupon
+		amount     float64
+		discount   float64
+		later      float64
+		fromWallet float64
+		ref        string
+	)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		discounts := make([]float64, len(bookings))
+		if req.PromoCode != "" {
+			var err error
+			coupon, discounts, err = services.ApplyCoupon(tx, req.PromoCode, bookings[0].UserID, bookings)
+			if err != nil {
+				return err
+			}
+			for _, d := range discounts {
+				discount += d
+			}
+			items = services.DiscountItems(items, discount, currency, coupon.Code)
+			metadata["promo_code"] = coupon.Code
+		}
+
+		dues := make([]int64, len(bookings))
+		for i := range bookings {
+			due := bookings[i].TotalPrice - discounts[i]
+			if purpose == services.PurposeBalance {
+				due = services.BalanceDue(&bookings[i])
+			}
+			dues[i] = services.ToMinorUnits(due, currency)
+		}
+
+		// The rest of a deposit is paid later
+		if purpose == services.PurposeDeposit {
+			var rest int64
+			for i, b := range bookings {
+				deposit := services.DepositDue(dues[i], b.Field.DepositPercent)
+				rest += dues[i] - deposit
+				dues[i] = deposit
+			}
+			later = services.FromMinorUnits(rest, currency)
+			items = services.ReduceItems(items, later, currency, fmt.Sprintf("%g%% deposit", bookings[0].Field.DepositPercent))
+		}
+
+		for _, due := range dues {
+			amount += services.FromMinorUnits(due, currency)
+		}
+		amount = services.RoundAmount(amount, currency)
+
+		walletParts := make([]int64, len(bookings))
+		if req.UseWallet || req.WalletAmount > 0 {
+			var err error
+			fromWallet, err = services.WalletPortion(tx, bookings[0].UserID, currency, amount, req.WalletAmount)
+			if err != nil {
+				return err
+			}
+			walletParts = services.SplitProportionally(services.ToMinorUnits(fromWallet, currency), dues)
+			items = services.ReduceItems(items, fromWallet, currency, "part paid from wallet")
+		}
+
+		// The payments reserve the promo code and the wallet balance; the
+		// checkout session is opened once they are committed
+		ref = "wallet_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		if fromWallet < amount {
+			ref = services.NewReservationRef()
+		}
+
+		records := make([]models.Payment, 0, len(bookings))
+		for i, b := range bookings {
+			parts := []struct {
+				provider string
+				amount   int64
+			}{
+				{provider.Name(), dues[i] - walletParts[i]},
+				{services.WalletProvider, walletParts[i]},
+			}
+			first := true
+			for _, part := range parts {
+				if part.amount <= 0 {
+					continue
+				}
+				p := models.Payment{
+					ID:          uuid.New(),
+					BookingID:   b.ID,
+					Amount:      services.FromMinorUnits(part.amount, currency),
+					Currency:    currency,
+					Status:      "pending",
+					StripeRefID: ref,
+					Provider:    part.provider,
+					Purpose:     purpose,
+				}
+				// The discount is recorded once per booking
+				if first {
+					p.DiscountAmount = discounts[i]
+					if coupon != nil {
+						p.CouponID = &coupon.ID
+						p.PromoCode = coupon.Code
+					}
+					first = false
+				}
+				records = append(records, p)
+			}
+		}
+		if err := tx.Create(&records).Error; err != nil {
+			return err
+		}
+
+		if fromWallet >= amount {
+			// Paid in full from the wallet
+			return services.MarkCheckoutSucceeded(tx, ref)
+		}
+		return nil
 	})
+	switch {
+	case errors.Is(err, services.ErrInvalidCoupon):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrWalletCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment record"})
+		return
+	}
+
+	if fromWallet < amount {
+		s, err = services.OpenReservedCheckout(config.DB, provider, ref, currency, items, metadata)
+		if err != nil {
+			checkoutError(c, provider, err)
+			return
+		}
+		ref = s.ID
+	}
+
+	resp := dto.CreateCheckoutSessionResponse{
+		SessionID:      ref,
+		Amount:         amount,
+		Currency:       currency,
+		DiscountAmount: discount,
+		WalletAmount:   fromWallet,
+		Paid:           s == nil,
+		Purpose:        purpose,
+		BalanceDue:     later,
+	}
+	if s != nil {
+		resp.SessionURL = s.URL
+	}
+	if coupon != nil {
+		resp.PromoCode = coupon.Code
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// checkoutError responds to a failed checkout session creation.
+func checkoutError(c *gin.Context, provider payments.Provider, err error) {
+	if errors.Is(err, payments.ErrUnsupportedCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Payment provider %s does not support this currency", provider.Name())})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 // StripeWebhook godoc
 // @Summary Stripe webhook
-// @Description Handle Stripe webhook events to update payment and booking status.
+// @Description Handle Stripe webhook events to update payment and booking status: checkout completion (including delayed payment methods), expiry and failures, refunds made in the Stripe dashboard and disputes.
 // @Tags payments
 // @Accept json
 // @Produce json
@@ -120,142 +389,116 @@ func CreateCheckoutSession(c *gin.Context) {
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /payments/stripe-webhook [post]
 func StripeWebhook(c *gin.Context) {
-	endpointSecret := os.Getenv("STRIPE_WEBHOOK_SECRET")
+	handleWebhook(c, "stripe")
+}
+
+// PaymentWebhook godoc
+// @Summary Payment provider webhook
+// @Description Handle webhook events of a payment provider (stripe, midtrans, or fake with PAYMENT_PROVIDER=fake) to update payment and booking status.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/webhooks/{provider} [post]
+func PaymentWebhook(c *gin.Context) {
+	handleWebhook(c, c.Param("provider"))
+}
+
+// MidtransNotification godoc
+// @Summary Midtrans payment notification
+// @Description Handle Midtrans HTTP notifications. The signature_key (SHA512 of order_id, status_code, gross_amount and the server key) is verified; settlement and accepted capture confirm the booking, expire and cancel expire the payment, deny and failure mark it failed.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/midtrans/notification [post]
+func MidtransNotification(c *gin.Context) {
+	handleWebhook(c, "midtrans")
+}
+
+// handleWebhook verifies a webhook request with the named provider, stores
+// its event and applies it once.
+func handleWebhook(c *gin.Context, providerName string) {
+	provider, err := payments.Get(providerName)
+	if err != nil || providerName == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown payment provider"})
+		return
+	}
+	// Fake webhooks are unsigned, they are only accepted with PAYMENT_PROVIDER=fake
+	if _, fake := provider.(*payments.FakeProvider); fake && provider != payments.Default {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fake payment provider is not enabled"})
+		return
+	}
+
 	payload, err := c.GetRawData()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
 		return
 	}
-	sigHeader := c.GetHeader("Stripe-Signature")
 
-	event, err := webhook.ConstructEvent(payload, sigHeader, endpointSecret)
+	event, err := provider.VerifyWebhook(payload, c.Request.Header)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook signature"})
 		return
 	}
 
-	switch event.Type {
-	case "checkout.session.completed":
-		var session stripe.CheckoutSession
-		if err := json.Unmarshal(event.Data.Raw, &session); err == nil {
-			// Update payment status
-			result := config.DB.Model(&models.Payment{}).
-				Where("stripe_ref_id = ?", session.ID).
-				Update("status", "succeeded")
-
-			if result.Error != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
-				return
-			}
-
-			// Update booking status to confirmed
-			if bookingID, exists := session.Metadata["booking_id"]; exists {
-				config.DB.Model(&models.Booking{}).
-					Where("id = ?", bookingID).
-					Update("status", "confirmed")
-			}
-		}
-
-	case "checkout.session.expired", "checkout.session.async_payment_failed":
-		var session stripe.CheckoutSession
-		if err := json.Unmarshal(event.Data.Raw, &session); err == nil {
-			config.DB.Model(&models.Payment{}).
-				Where("stripe_ref_id = ?", session.ID).
-				Update("status", "failed")
-		}
+	duplicate, err := services.ProcessWebhookEvent(config.DB, provider.Name(), payload, event)
+	if err != nil {
+		// Non-2xx makes the provider retry; the failure is stored for replay
+		log.Printf("webhook %s %s failed: %v", provider.Name(), event.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
+		return
+	}
+	if duplicate {
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "received"})
 }
 
-// StripeWebhookTest godoc
-// @Summary Test Stripe webhook (Development only)
-// @Description Test webhook endpoint without signature validation for development/testing purposes.
+// CompleteFakeCheckout godoc
+// @Summary Pay a fake checkout session (fake provider only)
+// @Description Simulate the customer paying a checkout session of the in-process fake payment provider and deliver its webhook. Only available with PAYMENT_PROVIDER=fake.
 // @Tags payments
-// @Accept json
 // @Produce json
-// @Param webhook_data body object true "Test webhook payload"
-// @Success 200 {object} object
+// @Param id path string true "Checkout session ID"
+// @Success 200 {object} dto.MessageResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /payments/stripe-webhook-test [post]
-func StripeWebhookTest(c *gin.Context) {
-	var payload map[string]interface{}
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON payload"})
+// @Router /payments/fake/{id}/complete [post]
+func CompleteFakeCheckout(c *gin.Context) {
+	fake, ok := payments.Default.(*payments.FakeProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fake payment provider is not enabled"})
 		return
 	}
 
-	// Log payload untuk debugging
-	log.Printf("Test webhook received: %+v", payload)
-
-	// Simulasi pemrosesan event
-	eventType, exists := payload["type"].(string)
-	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing event type"})
+	s, err := fake.CompleteSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	switch eventType {
-	case "checkout.session.completed":
-		// Simulasi update payment status
-		if data, ok := payload["data"].(map[string]interface{}); ok {
-			if object, ok := data["object"].(map[string]interface{}); ok {
-				sessionID, _ := object["id"].(string)
-
-				// Update payment status
-				result := config.DB.Model(&models.Payment{}).
-					Where("stripe_ref_id = ?", sessionID).
-					Update("status", "succeeded")
-
-				if result.Error != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
-					return
-				}
-
-				// Update booking status jika ada metadata
-				if metadata, ok := object["metadata"].(map[string]interface{}); ok {
-					if bookingID, ok := metadata["booking_id"].(string); ok {
-						config.DB.Model(&models.Booking{}).
-							Where("id = ?", bookingID).
-							Update("status", "confirmed")
-					}
-				}
-
-				c.JSON(http.StatusOK, gin.H{
-					"status":     "test webhook processed",
-					"event_type": eventType,
-					"session_id": sessionID,
-				})
-				return
-			}
-		}
-
-	case "checkout.session.expired", "checkout.session.async_payment_failed":
-		// Simulasi payment failed
-		if data, ok := payload["data"].(map[string]interface{}); ok {
-			if object, ok := data["object"].(map[string]interface{}); ok {
-				sessionID, _ := object["id"].(string)
-
-				config.DB.Model(&models.Payment{}).
-					Where("stripe_ref_id = ?", sessionID).
-					Update("status", "failed")
-
-				c.JSON(http.StatusOK, gin.H{
-					"status":     "test webhook processed",
-					"event_type": eventType,
-					"session_id": sessionID,
-				})
-				return
-			}
-		}
+	payload := fake.WebhookPayload(payments.EventCheckoutCompleted, s.ID)
+	event, err := fake.VerifyWebhook(payload, nil)
+	if err == nil {
+		_, err = services.ProcessWebhookEvent(config.DB, fake.Name(), payload, event)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":     "test webhook received",
-		"event_type": eventType,
-		"note":       "Event type not handled in test mode",
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Checkout session " + s.ID + " paid"})
 }
 
 // GetPayments godoc
@@ -321,11 +564,23 @@ func GetMyPayments(c *gin.Context) {
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /payments/{id} [get]
 func GetPaymentByID(c *gin.Context) {
+	payment, ok := findAccessiblePayment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// findAccessiblePayment loads the payment in the id path param with its
+// booking, user and field. Users only see their own payments, admins all.
+// It writes the error response and returns false if there is none.
+func findAccessiblePayment(c *gin.Context) (*models.Payment, bool) {
 	paymentID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
+		return nil, false
 	}
 
 	var payment models.Payment
@@ -342,8 +597,62 @@ func GetPaymentByID(c *gin.Context) {
 
 	if err := query.First(&payment).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return nil, false
+	}
+	return &payment, true
+}
+
+// GetPaymentReceipt godoc
+// @Summary Get payment receipt
+// @Description Download the receipt (invoice) of a paid payment as PDF or HTML. The invoice number is assigned when the payment succeeds and never changes; refunds made since are listed on the receipt.
+// @Tags payments
+// @Security BearerAuth
+// @Produce application/pdf
+// @Produce text/html
+// @Param id path string true "Payment ID"
+// @Param format query string false "pdf (default) or html"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/{id}/receipt [get]
+func GetPaymentReceipt(c *gin.Context) {
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf or html"})
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	payment, ok := findAccessiblePayment(c)
+	if !ok {
+		return
+	}
+	if payment.Status != "succeeded" && payment.Status != "refunded" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Receipts are only available for paid payments"})
+		return
+	}
+
+	// Payments made before invoices existed get their number now
+	invoice, err := services.IssueInvoice(config.DB, payment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue invoice"})
+		return
+	}
+	receipt := services.BuildReceipt(payment, invoice)
+
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = receipts.WritePDF(&buf, receipt)
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, receipt.Filename("pdf")))
+	} else {
+		err = receipts.WriteHTML(&buf, receipt)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render receipt"})
+		return
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
 }
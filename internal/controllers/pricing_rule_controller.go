@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetPricingRules godoc
+// @Summary List a field's pricing rules (Admin only)
+// @Description List the pricing rules of a field ordered by priority.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Success 200 {array} models.PricingRule
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/pricing-rules [get]
+func GetPricingRules(c *gin.Context) {
+	var rules []models.PricingRule
+	if err := config.DB.Where("field_id = ?", c.Param("id")).
+		Order("priority DESC, created_at").
+		Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pricing rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreatePricingRule godoc
+// @Summary Create a pricing rule (Admin only)
+// @Description Create a pricing rule that overrides the field's hourly price for matching weekdays, time-of-day window and date range.
+// @Tags fields
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param input body dto.PricingRuleRequest true "Pricing rule"
+// @Success 201 {object} models.PricingRule
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/pricing-rules [post]
+func CreatePricingRule(c *gin.Context) {
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	var req dto.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.PricingRule{FieldID: field.ID}
+	applyPricingRuleRequest(&rule, &req)
+	if err := services.ValidatePricingRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pricing rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePricingRule godoc
+// @Summary Update a pricing rule (Admin only)
+// @Description Replace all attributes of a pricing rule.
+// @Tags fields
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Param input body dto.PricingRuleRequest true "Pricing rule"
+// @Success 200 {object} models.PricingRule
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/pricing-rules/{rule_id} [put]
+func UpdatePricingRule(c *gin.Context) {
+	var rule models.PricingRule
+	if err := config.DB.First(&rule, "id = ? AND field_id = ?", c.Param("rule_id"), c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pricing rule not found"})
+		return
+	}
+
+	var req dto.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyPricingRuleRequest(&rule, &req)
+	if err := services.ValidatePricingRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.DB.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pricing rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeletePricingRule godoc
+// @Summary Delete a pricing rule (Admin only)
+// @Description Delete a pricing rule of a field.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /fields/admin/{id}/pricing-rules/{rule_id} [delete]
+func DeletePricingRule(c *gin.Context) {
+	result := config.DB.Where("id = ? AND field_id = ?", c.Param("rule_id"), c.Param("id")).
+		Delete(&models.PricingRule{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete pricing rule"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pricing rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule deleted successfully"})
+}
+
+// GetFieldQuote godoc
+// @Summary Get a price quote
+// @Description Get the price breakdown of booking a field for a period, applying the field's pricing rules per hour segment.
+// @Tags fields
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param start_time query string true "Start time (RFC3339)"
+// @Param end_time query string true "End time (RFC3339)"
+// @Success 200 {object} services.PriceQuote
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /fields/{id}/quote [get]
+func GetFieldQuote(c *gin.Context) {
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339, c.Query("start_time"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing start_time, expected RFC3339"})
+		return
+	}
+	end, err := time.Parse(time.RFC3339, c.Query("end_time"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing end_time, expected RFC3339"})
+		return
+	}
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be after start_time"})
+		return
+	}
+
+	quote, err := services.QuoteBooking(config.DB, &field, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func applyPricingRuleRequest(rule *models.PricingRule, req *dto.PricingRuleRequest) {
+	rule.Name = req.Name
+	rule.PricePerHour = req.PricePerHour
+	rule.Weekdays = req.Weekdays
+	rule.StartTime = req.StartTime
+	rule.EndTime = req.EndTime
+	rule.StartDate = req.StartDate
+	rule.EndDate = req.EndDate
+	rule.Priority = req.Priority
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetSessions godoc
+// @Summary List my sessions
+// @Description List the active logins of the current user, one per device, most recently used first. The session of the request is marked current.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.SessionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/sessions [get]
+func GetSessions(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := services.ListSessions(uid.String())
+	if err != nil {
+		if errors.Is(err, services.ErrSessionsDisabled) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Redis not available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := c.GetString("session_id")
+	res := make([]dto.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		res[i] = dto.SessionResponse{
+			ID:         s.ID,
+			Device:     s.Device,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			Current:    s.ID == current,
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Log out one device of the current user: its refresh token and access tokens stop working. Revoking the current session logs out the request's own device.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := services.RevokeSession(uid.String(), c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	case errors.Is(err, services.ErrSessionsDisabled):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Redis not available"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAllSessions godoc
+// @Summary Log out everywhere
+// @Description Revoke every session of the current user. With keep_current=true the session of the request stays logged in.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param keep_current query bool false "Keep the current session"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/sessions [delete]
+func RevokeAllSessions(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if config.RedisClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Redis not available"})
+		return
+	}
+
+	keep := ""
+	if c.Query("keep_current") == "true" {
+		keep = c.GetString("session_id")
+	}
+	if err := services.RevokeUserSessions(uid.String(), keep); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked"})
+}
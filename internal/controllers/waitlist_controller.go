@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// JoinWaitlist godoc
+// @Summary Join the waitlist of a booked slot
+// @Description Queue for a field/time window that is already booked. When the slot is freed (cancellation or expired hold), the first user in line gets it as a pending booking held for a limited time and is notified by email.
+// @Tags waitlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.JoinWaitlistRequest true "Wanted slot"
+// @Success 201 {object} models.WaitlistEntry
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /waitlist [post]
+func JoinWaitlist(c *gin.Context) {
+	var req dto.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	uid, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user_id in token"})
+		return
+	}
+
+	fieldID, err := uuid.Parse(req.FieldID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field_id format"})
+		return
+	}
+
+	var field models.Field
+	if err := config.DB.First(&field, "id = ?", fieldID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	// Validate time
+	if !req.StartTime.Before(req.EndTime) || req.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking time"})
+		return
+	}
+
+	if err := services.CheckOpeningHours(config.DB, fieldID, req.StartTime, req.EndTime); err != nil {
+		if errors.Is(err, services.ErrFieldClosed) || errors.Is(err, services.ErrOutsideOpeningHours) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate opening hours"})
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		UserID:    uid,
+		FieldID:   fieldID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if err := services.JoinWaitlist(config.DB, &entry); err != nil {
+		switch {
+		case errors.Is(err, services.ErrSlotAvailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAlreadyWaitlisted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		}
+		return
+	}
+
+	entry.Field = field
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetMyWaitlist godoc
+// @Summary Get my waitlist entries
+// @Description List the waitlist entries of the current user, newest first. Offered entries reference the held booking to pay.
+// @Tags waitlist
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.WaitlistEntry
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /waitlist/me [get]
+func GetMyWaitlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var entries []models.WaitlistEntry
+	if err := config.DB.Preload("Field").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave the waitlist
+// @Description Remove a waiting or offered entry from the waitlist. A booking held for an offered entry is cancelled and the slot passes to the next user.
+// @Tags waitlist
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Waitlist entry ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /waitlist/{id} [delete]
+func LeaveWaitlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var entry models.WaitlistEntry
+	if err := config.DB.First(&entry, "id = ? AND user_id = ?", c.Param("id"), userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return
+	}
+
+	if entry.Status != "waiting" && entry.Status != "offered" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Waitlist entry is already " + entry.Status})
+		return
+	}
+
+	offered := entry.Status == "offered"
+	if err := config.DB.Model(&entry).Update("status", "cancelled").Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+
+	// Give up the held booking so the next user is offered the slot
+	if offered && entry.BookingID != nil {
+		var booking models.Booking
+		if err := config.DB.Preload("Payments").
+			First(&booking, "id = ? AND status = ?", *entry.BookingID, "pending").Error; err == nil {
+			if _, err := services.CancelBooking(config.DB, &booking, false); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel offered booking: " + err.Error()})
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist successfully"})
+}
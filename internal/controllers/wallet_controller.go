@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetMyWallet godoc
+// @Summary Get my wallet
+// @Description Get the wallet balance of the authenticated user. Available is the balance minus what open checkouts paid partly from the wallet reserve.
+// @Tags wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.WalletResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /wallet [get]
+func GetMyWallet(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := services.GetWallet(config.DB, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+		return
+	}
+	available, err := services.AvailableBalance(config.DB, wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WalletResponse{
+		Balance:   wallet.Balance,
+		Available: available,
+		Currency:  wallet.Currency,
+	})
+}
+
+// GetMyWalletEntries godoc
+// @Summary Get my wallet history
+// @Description List the wallet ledger entries of the authenticated user, newest first: top-ups and refunds credit the wallet (positive amount), bookings paid from it debit it (negative amount).
+// @Tags wallet
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of entries (default 50, max 200)"
+// @Success 200 {array} models.WalletEntry
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /wallet/entries [get]
+func GetMyWalletEntries(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+
+	var entries []models.WalletEntry
+	if err := config.DB.Where("user_id = ? AND account = ?", uid, services.AccountWallet).
+		Order("created_at DESC").Limit(limit).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// CreateWalletTopUp godoc
+// @Summary Top up my wallet
+// @Description Open a checkout session adding money to the wallet of the authenticated user. The wallet is credited when the payment succeeds.
+// @Tags wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.WalletTopUpRequest true "Top-up amount"
+// @Success 200 {object} dto.WalletTopUpResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /wallet/top-ups [post]
+func CreateWalletTopUp(c *gin.Context) {
+	var req dto.WalletTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	provider, err := payments.Selectable(req.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topUp, s, err := services.CreateWalletTopUp(config.DB, provider, uid, req.Amount)
+	if err != nil {
+		checkoutError(c, provider, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WalletTopUpResponse{
+		TopUpID:    topUp.ID.String(),
+		SessionID:  s.ID,
+		SessionURL: s.URL,
+		Amount:     topUp.Amount,
+		Currency:   topUp.Currency,
+	})
+}
+
+// currentUserID returns the authenticated user's ID. It writes the error
+// response and returns false if there is none.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, false
+	}
+	uid, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user_id in token"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
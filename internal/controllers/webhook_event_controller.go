@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// GetWebhookEvents godoc
+// @Summary List received webhook events (admin)
+// @Description List stored payment webhook events, newest first (at most 100). Filter by status: failed (last attempt failed), processed or pending.
+// @Tags payments
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "failed, processed or pending"
+// @Param provider query string false "Payment provider, e.g. stripe"
+// @Success 200 {array} models.WebhookEvent
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/webhook-events [get]
+func GetWebhookEvents(c *gin.Context) {
+	query := config.DB.Order("created_at DESC").Limit(100)
+
+	switch c.Query("status") {
+	case "":
+	case "failed":
+		query = query.Where("processed_at IS NULL AND error <> ''")
+	case "processed":
+		query = query.Where("processed_at IS NOT NULL")
+	case "pending":
+		query = query.Where("processed_at IS NULL AND (error = '' OR error IS NULL)")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be failed, processed or pending"})
+		return
+	}
+	if provider := c.Query("provider"); provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+
+	var events []models.WebhookEvent
+	if err := query.Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// ReplayWebhookEvent godoc
+// @Summary Replay a webhook event (admin)
+// @Description Process a stored webhook event that has not been processed, e.g. after fixing the cause of its failure. The stored payload is applied without re-checking its signature.
+// @Tags payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Webhook event ID"
+// @Success 200 {object} models.WebhookEvent
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/webhook-events/{id}/replay [post]
+func ReplayWebhookEvent(c *gin.Context) {
+	var event models.WebhookEvent
+	if err := config.DB.First(&event, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook event not found"})
+		return
+	}
+
+	if err := services.ReplayWebhookEvent(config.DB, &event); err != nil {
+		if errors.Is(err, services.ErrEventProcessed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Webhook event has already been processed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Replay failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // RegisterRequest represents the request body for user registration
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required,min=2" example:"John Doe"`
@@ -30,6 +32,17 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
 
+// ForgotPasswordRequest represents the request body for requesting a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john.doe@example.com"`
+}
+
+// ResetPasswordRequest represents the request body for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required" example:"q3J8yF0aT6m..."`
+	Password string `json:"password" binding:"required,min=6" example:"newpassword123"`
+}
+
 // MessageResponse represents a simple message response
 type MessageResponse struct {
 	Message string `json:"message" example:"Operation successful"`
@@ -39,3 +52,68 @@ type MessageResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
 }
+
+// MFAChallengeResponse is returned instead of tokens when the user has two-factor
+// authentication enabled. The token is exchanged with a code at /auth/mfa/verify.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"Xk2p9Qm..."`
+	ExpiresIn   int64  `json:"expires_in" example:"300"`
+}
+
+// MFAVerifyRequest represents the request body for completing a login with a second factor
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"Xk2p9Qm..."`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+// MFACodeRequest represents a request confirmed with a TOTP code or a recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// MFAEnableRequest represents the request body for turning on two-factor
+// authentication; the enrollment token is required for roles that must use it
+type MFAEnableRequest struct {
+	Code            string `json:"code" binding:"required" example:"123456"`
+	EnrollmentToken string `json:"enrollment_token,omitempty" example:"Xk2p9Qm..."`
+}
+
+// MFASetupResponse is the TOTP secret to add to an authenticator app
+type MFASetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/BookMyField:admin%40admin.com?secret=JBSW..."`
+	// Set when an enrollment token was emailed, see MFAEnableRequest
+	EnrollmentTokenSent bool `json:"enrollment_token_sent,omitempty" example:"true"`
+}
+
+// MFAEnableResponse holds the recovery codes, shown only once, and new tokens
+// issued with the second factor
+type MFAEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3m9x-7qp2d"`
+	LoginResponse
+}
+
+// RecoveryCodesResponse holds new recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3m9x-7qp2d"`
+}
+
+// MFAStatusResponse describes the two-factor authentication of the current user
+type MFAStatusResponse struct {
+	Enabled           bool       `json:"enabled" example:"true"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	Required          bool       `json:"required" example:"true"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left" example:"10"`
+}
+
+// SessionResponse is a login of the current user on a device
+type SessionResponse struct {
+	ID         string    `json:"id" example:"0b6f5c1e-3f0a-4a53-9d8e-2f1f6d1c9a10"`
+	Device     string    `json:"device" example:"Chrome on Windows"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) ..."`
+	IP         string    `json:"ip" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current" example:"true"`
+}
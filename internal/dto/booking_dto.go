@@ -1,9 +1,72 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+)
 
 // CreateBookingRequest represents the request body for creating a booking
 type CreateBookingRequest struct {
+	FieldID    string             `json:"field_id" binding:"required" example:"c1f8e4d9-8a2b-4b6e-9c1d-5a8f8c7b6a5d"`
+	StartTime  time.Time          `json:"start_time" binding:"required" example:"2024-09-15T10:00:00Z"`
+	EndTime    time.Time          `json:"end_time" binding:"required" example:"2024-09-15T12:00:00Z"`
+	Notes      string             `json:"notes,omitempty" example:"Futsal league match"`
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"`
+}
+
+// RecurrenceRequest makes a booking repeat weekly or every other week.
+// Either Until or Count (max 52) must be given; if both are set the series stops at whichever comes first.
+type RecurrenceRequest struct {
+	Frequency string     `json:"frequency" binding:"required,oneof=weekly biweekly" example:"weekly"`
+	Until     *time.Time `json:"until,omitempty" example:"2024-12-31T23:59:59+07:00"`
+	Count     int        `json:"count,omitempty" binding:"omitempty,min=1,max=52" example:"12"`
+}
+
+// OccurrenceConflict describes an occurrence of a recurring booking that could not be booked
+type OccurrenceConflict struct {
+	StartTime time.Time `json:"start_time" example:"2024-09-24T19:00:00+07:00"`
+	EndTime   time.Time `json:"end_time" example:"2024-09-24T21:00:00+07:00"`
+	Reason    string    `json:"reason" example:"field is already booked for this time slot"`
+}
+
+// CreateBookingSeriesResponse represents the response for creating a recurring booking
+type CreateBookingSeriesResponse struct {
+	Series    models.BookingSeries `json:"series"`
+	Conflicts []OccurrenceConflict `json:"conflicts"`
+}
+
+// CancelBookingSeriesResponse represents the response for cancelling a booking series
+type CancelBookingSeriesResponse struct {
+	Message   string                  `json:"message" example:"Booking series cancelled"`
+	Cancelled int                     `json:"cancelled" example:"8"`
+	Refunds   []CancelBookingResponse `json:"refunds,omitempty"`
+	Failed    map[string]string       `json:"failed,omitempty"` // booking ID -> error
+}
+
+// RescheduleBookingRequest represents the request body for moving a booking.
+// FieldID is optional and defaults to the booking's current field.
+type RescheduleBookingRequest struct {
+	FieldID   string    `json:"field_id,omitempty" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	StartTime time.Time `json:"start_time" binding:"required" example:"2025-08-20T15:00:00Z"`
+	EndTime   time.Time `json:"end_time" binding:"required" example:"2025-08-20T17:00:00Z"`
+}
+
+// RescheduleBookingResponse represents the response for rescheduling a booking.
+// A higher price of a paid booking is charged through the top-up checkout
+// session, a lower one is refunded.
+type RescheduleBookingResponse struct {
+	Booking           models.Booking `json:"booking"`
+	PreviousTotal     float64        `json:"previous_total" example:"400000"`
+	AmountDue         float64        `json:"amount_due,omitempty" example:"100000"`
+	CheckoutSessionID string         `json:"checkout_session_id,omitempty" example:"cs_test_..."`
+	CheckoutURL       string         `json:"checkout_url,omitempty" example:"https://checkout.stripe.com/pay/cs_test_..."`
+	RefundedAmount    float64        `json:"refunded_amount,omitempty" example:"100000"`
+	RefundID          string         `json:"refund_id,omitempty" example:"re_..."`
+}
+
+// JoinWaitlistRequest represents the request body for joining the waitlist of a booked slot
+type JoinWaitlistRequest struct {
 	FieldID   string    `json:"field_id" binding:"required" example:"c1f8e4d9-8a2b-4b6e-9c1d-5a8f8c7b6a5d"`
 	StartTime time.Time `json:"start_time" binding:"required" example:"2024-09-15T10:00:00Z"`
 	EndTime   time.Time `json:"end_time" binding:"required" example:"2024-09-15T12:00:00Z"`
@@ -14,6 +77,8 @@ type CreateFieldRequest struct {
 	Name     string  `json:"name" binding:"required" example:"Lapangan Futsal A"`
 	Location string  `json:"location" binding:"required" example:"Jakarta"`
 	Price    float64 `json:"price" binding:"required" example:"200000"`
+
+	DepositPercent float64 `json:"deposit_percent,omitempty" example:"30"` // charged at checkout, 0 for the full price
 }
 
 // UpdateFieldRequest represents the request body for updating a field
@@ -21,22 +86,48 @@ type UpdateFieldRequest struct {
 	Name     string  `json:"name" binding:"required" example:"Lapangan Futsal A Updated"`
 	Location string  `json:"location" binding:"required" example:"Jakarta Barat"`
 	Price    float64 `json:"price" binding:"required" example:"250000"`
+
+	DepositPercent *float64 `json:"deposit_percent,omitempty" example:"30"` // charged at checkout, 0 for the full price
 }
 
-// CreateCheckoutSessionRequest represents the request body for creating a Stripe checkout session
+// CreateCheckoutSessionRequest represents the request body for creating a Stripe checkout session.
+// Either BookingID or SeriesID (to pay all pending occurrences of a recurring booking at once) is required.
+// Provider selects the payment gateway (stripe, midtrans or fake); the configured default is used when empty.
+// UseWallet pays from the wallet balance, at most WalletAmount when it is set.
+// Bookings of fields that take a deposit are charged only the deposit unless PayInFull is set;
+// for a partially paid booking the checkout charges the balance.
 type CreateCheckoutSessionRequest struct {
-	BookingID string `json:"booking_id" binding:"required" example:"c1f8e4d9-8a2b-4b6e-9c1d-5a8f8c7b6a5d"`
+	BookingID string `json:"booking_id" binding:"required_without=SeriesID" example:"c1f8e4d9-8a2b-4b6e-9c1d-5a8f8c7b6a5d"`
+	SeriesID  string `json:"series_id,omitempty" example:"5b0e6d1c-2f7a-4c8e-9d3b-1a2b3c4d5e6f"`
+	Provider  string `json:"provider,omitempty" example:"midtrans"`
+	PromoCode string `json:"promo_code,omitempty" example:"WEEKEND10"`
+
+	UseWallet    bool    `json:"use_wallet,omitempty" example:"true"`
+	WalletAmount float64 `json:"wallet_amount,omitempty" binding:"omitempty,gte=0" example:"100000"`
+
+	PayInFull bool `json:"pay_in_full,omitempty" example:"false"`
 }
 
 // CreateCheckoutSessionResponse represents the response for creating a Stripe checkout session
 type CreateCheckoutSessionResponse struct {
-	SessionID  string `json:"session_id" example:"cs_test_..."`
-	SessionURL string `json:"session_url" example:"https://checkout.stripe.com/pay/cs_test_..."`
+	SessionID      string  `json:"session_id" example:"cs_test_..."`
+	SessionURL     string  `json:"session_url" example:"https://checkout.stripe.com/pay/cs_test_..."`
+	Amount         float64 `json:"amount" example:"360000"` // after the discount, wallet part included
+	Currency       string  `json:"currency" example:"idr"`
+	PromoCode      string  `json:"promo_code,omitempty" example:"WEEKEND10"`
+	DiscountAmount float64 `json:"discount_amount,omitempty" example:"40000"`
+	WalletAmount   float64 `json:"wallet_amount,omitempty" example:"100000"` // paid from the wallet
+	Paid           bool    `json:"paid,omitempty" example:"false"`           // true when the wallet paid everything; session_url is then empty
+	Purpose        string  `json:"purpose,omitempty" example:"deposit"`      // deposit or balance; empty when paying in full
+	BalanceDue     float64 `json:"balance_due,omitempty" example:"252000"`   // left to pay after the deposit
 }
 
 // CancelBookingResponse represents the response for cancelling a booking
 type CancelBookingResponse struct {
-	Message      string `json:"message" example:"Booking cancelled and payment refunded successfully"`
-	RefundID     string `json:"refund_id,omitempty" example:"re_..."`
-	RefundStatus string `json:"refund_status,omitempty" example:"succeeded"`
+	Message        string   `json:"message" example:"Booking cancelled and payment refunded successfully"`
+	RefundID       string   `json:"refund_id,omitempty" example:"re_..."`
+	RefundStatus   string   `json:"refund_status,omitempty" example:"succeeded"`
+	RefundedAmount float64  `json:"refunded_amount,omitempty" example:"200000"`
+	RefundPercent  *float64 `json:"refund_percent,omitempty" example:"50"`
+	Policy         string   `json:"cancellation_policy,omitempty" example:"Standard"`
 }
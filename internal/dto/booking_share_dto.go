@@ -0,0 +1,20 @@
+package dto
+
+// SplitBookingRequest represents the request body for splitting a booking between participants.
+// Either every participant has an amount and the owner pays the rest, or none has and the
+// total is split equally between the owner and the participants.
+type SplitBookingRequest struct {
+	Participants []ShareParticipant `json:"participants" binding:"required,min=1,max=20,dive"`
+}
+
+// ShareParticipant is a participant invited to pay a share of a booking
+type ShareParticipant struct {
+	Email  string  `json:"email" binding:"required,email" example:"teammate@example.com"`
+	Amount float64 `json:"amount,omitempty" binding:"gte=0" example:"80000"`
+}
+
+// ShareCheckoutRequest represents the request body for paying booking shares.
+// Provider selects the payment gateway; the configured default is used when empty.
+type ShareCheckoutRequest struct {
+	Provider string `json:"provider,omitempty" example:"midtrans"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+)
+
+// CouponRequest represents the request body for creating or updating a promo code
+type CouponRequest struct {
+	Code           string     `json:"code" binding:"required,max=32" example:"WEEKEND10"`
+	Description    string     `json:"description" example:"10% off weekend bookings"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=percent fixed" example:"percent"`
+	DiscountValue  float64    `json:"discount_value" binding:"required,gt=0" example:"10"` // percent, or amount in the configured currency
+	ValidFrom      *time.Time `json:"valid_from" example:"2024-01-01T00:00:00+07:00"`
+	ValidUntil     *time.Time `json:"valid_until" example:"2024-12-31T23:59:59+07:00"`
+	MaxUses        *int       `json:"max_uses" binding:"omitempty,gte=1" example:"100"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" binding:"omitempty,gte=1" example:"1"`
+	FieldIDs       []string   `json:"field_ids" binding:"omitempty,dive,uuid"` // empty = all fields
+	Active         *bool      `json:"active" example:"true"`                   // default true
+}
+
+// CouponResponse is a promo code with the number of checkouts that used it
+type CouponResponse struct {
+	models.Coupon
+	Uses int64 `json:"uses" example:"12"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+// AvailabilitySlot represents a single slot in a field's availability grid
+type AvailabilitySlot struct {
+	StartTime time.Time `json:"start_time" example:"2024-09-15T10:00:00+07:00"`
+	EndTime   time.Time `json:"end_time" example:"2024-09-15T11:00:00+07:00"`
+	Status    string    `json:"status" example:"available"` // available, booked, closed, past
+}
+
+// DayAvailability represents all slots of a single calendar day
+type DayAvailability struct {
+	Date  string             `json:"date" example:"2024-09-15"`
+	Slots []AvailabilitySlot `json:"slots"`
+}
+
+// FieldAvailabilityResponse represents the response for the field availability endpoint
+type FieldAvailabilityResponse struct {
+	FieldID     string            `json:"field_id" example:"c1f8e4d9-8a2b-4b6e-9c1d-5a8f8c7b6a5d"`
+	Timezone    string            `json:"timezone" example:"Asia/Jakarta"`
+	SlotMinutes int               `json:"slot_minutes" example:"60"`
+	Days        []DayAvailability `json:"days"`
+}
+
+// OpeningHourRequest represents a single weekly opening interval
+type OpeningHourRequest struct {
+	Weekday   *int   `json:"weekday" binding:"required,min=0,max=6" example:"1"` // 0 = Sunday ... 6 = Saturday
+	OpenTime  string `json:"open_time" binding:"required" example:"08:00"`
+	CloseTime string `json:"close_time" binding:"required" example:"22:00"`
+}
+
+// UpdateOpeningHoursRequest represents the request body for replacing a field's weekly schedule.
+// An empty list makes the field open around the clock.
+type UpdateOpeningHoursRequest struct {
+	OpeningHours []OpeningHourRequest `json:"opening_hours" binding:"dive"`
+}
+
+// CreateClosureRequest represents the request body for closing a field on a date
+type CreateClosureRequest struct {
+	Date   string `json:"date" binding:"required" example:"2024-12-25"`
+	Reason string `json:"reason" example:"Christmas holiday"`
+}
+
+// PricingRuleRequest represents the request body for creating or updating a pricing rule
+type PricingRuleRequest struct {
+	Name         string  `json:"name" binding:"required" example:"Weekend evening"`
+	PricePerHour float64 `json:"price_per_hour" binding:"required,gt=0" example:"300000"`
+	Weekdays     []int   `json:"weekdays" example:"0,6"`          // 0 = Sunday ... 6 = Saturday, empty = every day
+	StartTime    string  `json:"start_time" example:"17:00"`      // HH:MM, empty = from midnight
+	EndTime      string  `json:"end_time" example:"23:00"`        // HH:MM, empty = until midnight
+	StartDate    string  `json:"start_date" example:"2024-01-01"` // YYYY-MM-DD, optional
+	EndDate      string  `json:"end_date" example:"2024-12-31"`   // YYYY-MM-DD, optional
+	Priority     int     `json:"priority" example:"10"`           // higher wins when rules overlap
+}
+
+// CancellationPolicyRequest represents the request body for setting a field's or the global cancellation policy
+type CancellationPolicyRequest struct {
+	Name  string                    `json:"name" binding:"required" example:"Standard"`
+	Tiers []CancellationTierRequest `json:"tiers" binding:"required,min=1,dive"`
+}
+
+// CancellationTierRequest refunds RefundPercent when cancelling at least MinHoursBefore hours before the start
+type CancellationTierRequest struct {
+	MinHoursBefore *float64 `json:"min_hours_before" binding:"required,gte=0" example:"48"`
+	RefundPercent  *float64 `json:"refund_percent" binding:"required,gte=0,lte=100" example:"100"`
+}
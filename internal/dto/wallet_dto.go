@@ -0,0 +1,24 @@
+package dto
+
+// WalletResponse represents a user's wallet balance
+type WalletResponse struct {
+	Balance   float64 `json:"balance" example:"150000"`
+	Available float64 `json:"available" example:"100000"` // balance minus what open checkouts reserve
+	Currency  string  `json:"currency" example:"idr"`
+}
+
+// WalletTopUpRequest represents the request body for adding money to the wallet.
+// Provider selects the payment gateway; the configured default is used when empty.
+type WalletTopUpRequest struct {
+	Amount   float64 `json:"amount" binding:"required,gt=0" example:"200000"`
+	Provider string  `json:"provider,omitempty" example:"midtrans"`
+}
+
+// WalletTopUpResponse represents the checkout session of a wallet top-up
+type WalletTopUpResponse struct {
+	TopUpID    string  `json:"top_up_id" example:"5b0e6d1c-2f7a-4c8e-9d3b-1a2b3c4d5e6f"`
+	SessionID  string  `json:"session_id" example:"cs_test_..."`
+	SessionURL string  `json:"session_url" example:"https://checkout.stripe.com/pay/cs_test_..."`
+	Amount     float64 `json:"amount" example:"200000"`
+	Currency   string  `json:"currency" example:"idr"`
+}
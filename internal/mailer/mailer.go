@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"log"
+	"os"
+)
+
+// Mailer sends plain-text emails to users.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// LogMailer writes emails to the log instead of sending them; used in
+// development and whenever no mail transport is configured.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("📧 Mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// Default is the mailer used by Send.
+var Default Mailer = LogMailer{}
+
+// Init sends mail through SMTP_HOST when it is set, and to the log
+// otherwise.
+func Init() {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("⚠️ SMTP_HOST not set, emails are written to the log")
+		return
+	}
+	m := SMTPMailer{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+	if m.Port == "" {
+		m.Port = "587"
+	}
+	if m.From == "" {
+		m.From = "BookMyField <no-reply@bookmyfield.com>"
+	}
+	Default = m
+	log.Printf("✅ Mail sent through SMTP %s:%s", m.Host, m.Port)
+}
+
+// Send sends an email through Default.
+func Send(to, subject, body string) error {
+	return Default.Send(to, subject, body)
+}
@@ -0,0 +1,145 @@
+package mailer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpSession is what a client sent to the test SMTP server.
+type smtpSession struct {
+	auth string // decoded AUTH PLAIN response
+	from string
+	rcpt []string
+	data []byte
+}
+
+// serveSMTP accepts one connection on a local listener and speaks just
+// enough SMTP, without STARTTLS, for net/smtp to deliver a message. The
+// session is sent on the returned channel when the client quits.
+func serveSMTP(t *testing.T) (host, port string, done <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250-8BITMIME")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				_, resp, _ := strings.Cut(arg, " ")
+				decoded, _ := base64.StdEncoding.DecodeString(resp)
+				s.auth = string(decoded)
+				tp.PrintfLine("235 2.7.0 Authentication successful")
+			case "MAIL":
+				s.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				if i := strings.Index(s.from, ">"); i >= 0 {
+					s.from = s.from[:i]
+				}
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				s.rcpt = append(s.rcpt, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				if s.data, err = tp.ReadDotBytes(); err != nil {
+					return
+				}
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				ch <- s
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port, ch
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	host, port, done := serveSMTP(t)
+	m := SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: "mailer",
+		Password: "secret",
+		From:     "BookMyField <no-reply@bookmyfield.com>",
+	}
+
+	subject := "Atur ulang kata sandi – BookMyField"
+	if err := m.Send("player@example.com", subject, "Halo,\nklik tautan ini.\n"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	s := <-done
+
+	if s.auth != "\x00mailer\x00secret" {
+		t.Errorf("AUTH PLAIN %q, want the configured credentials", s.auth)
+	}
+	if s.from != "no-reply@bookmyfield.com" {
+		t.Errorf("MAIL FROM %q, want the bare sender address", s.from)
+	}
+	if len(s.rcpt) != 1 || s.rcpt[0] != "player@example.com" {
+		t.Errorf("RCPT TO %q, want the recipient only", s.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(s.data))))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	for header, want := range map[string]string{
+		"From":                      "BookMyField <no-reply@bookmyfield.com>",
+		"To":                        "player@example.com",
+		"Mime-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "8bit",
+	} {
+		if got := msg.Header.Get(header); got != want {
+			t.Errorf("%s header %q, want %q", header, got, want)
+		}
+	}
+	if raw := msg.Header.Get("Subject"); !strings.HasPrefix(raw, "=?utf-8?") {
+		t.Errorf("Subject header %q is not encoded", raw)
+	}
+	if got, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil || got != subject {
+		t.Errorf("Subject %q (%v), want %q", got, err, subject)
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date header: %v", err)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if string(body) != "Halo,\nklik tautan ini.\n" {
+		t.Errorf("body %q, want the text with its line breaks", body)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/services"
 )
 
 var JwtSecret []byte
@@ -23,7 +24,19 @@ func init() {
 	JwtSecret = []byte(secret)
 }
 
+// AuthMiddleware authenticates the access token. Users whose role requires
+// two-factor authentication need a token issued after a second factor.
 func AuthMiddleware() gin.HandlerFunc {
+	return authenticate(true)
+}
+
+// AuthMiddlewareWithoutMFA authenticates the access token without requiring
+// two-factor authentication, for the endpoints users enroll in it with.
+func AuthMiddlewareWithoutMFA() gin.HandlerFunc {
+	return authenticate(false)
+}
+
+func authenticate(requireMFA bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -76,9 +89,25 @@ func AuthMiddleware() gin.HandlerFunc {
 				}
 			}
 
+			role, _ := claims["role"].(string)
+			if mfa, _ := claims["mfa"].(bool); requireMFA && !mfa && config.MFARequired(role) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication required, enable it or log in again with your code"})
+				c.Abort()
+				return
+			}
+
+			// Access tokens end with their session
+			sessionID, _ := claims["sid"].(string)
+			if sessionID != "" && !services.TouchSession(sessionID, c.ClientIP()) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+
 			// Store in context for use in handlers
 			c.Set("user_id", claims["user_id"])
 			c.Set("role", claims["role"])
+			c.Set("session_id", sessionID)
 		}
 
 		c.Next()
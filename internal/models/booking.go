@@ -15,10 +15,16 @@ type Booking struct {
 	FieldID uuid.UUID `gorm:"type:uuid;not null" json:"field_id"`
 	Field   Field     `gorm:"foreignKey:FieldID" json:"field"`
 
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
-	Status    string    `json:"status"` // pending, confirmed, canceled
-	Notes     string    `json:"notes,omitempty"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   time.Time  `json:"end_time"`
+	Status    string     `json:"status"` // pending, partially_paid (deposit paid), confirmed, cancelled
+	Notes     string     `json:"notes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // end of the payment hold while pending
+	SeriesID  *uuid.UUID `gorm:"type:uuid;index" json:"series_id,omitempty"`
+
+	TotalPrice float64            `json:"total_price"`
+	Currency   string             `gorm:"type:varchar(3)" json:"currency"`
+	PriceItems []BookingPriceItem `gorm:"foreignKey:BookingID" json:"price_items,omitempty"`
 
 	Payments []Payment `gorm:"foreignKey:BookingID" json:"payments"`
 
@@ -32,3 +38,11 @@ func (b *Booking) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// BeforeSave stores times in UTC so range comparisons also work on SQLite,
+// which compares timestamps as text.
+func (b *Booking) BeforeSave(tx *gorm.DB) (err error) {
+	b.StartTime = b.StartTime.UTC()
+	b.EndTime = b.EndTime.UTC()
+	return
+}
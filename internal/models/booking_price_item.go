@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookingPriceItem is one line of a booking's price breakdown.
+type BookingPriceItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	BookingID   uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
+	Description string    `json:"description"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Hours       float64   `json:"hours"`
+	UnitPrice   float64   `json:"unit_price"` // price per hour
+	Amount      float64   `json:"amount"`
+}
+
+func (i *BookingPriceItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return
+}
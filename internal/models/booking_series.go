@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookingSeries groups the occurrences of a recurring booking, e.g. the same
+// field every Tuesday 19:00 for a season.
+type BookingSeries struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FieldID   uuid.UUID  `gorm:"type:uuid;not null" json:"field_id"`
+	Frequency string     `gorm:"type:varchar(20);not null" json:"frequency"` // weekly, biweekly
+	Count     int        `json:"count,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+	Status    string     `gorm:"type:varchar(20);not null;default:active" json:"status"` // active, cancelled
+
+	Bookings []Booking `gorm:"foreignKey:SeriesID" json:"bookings,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *BookingSeries) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
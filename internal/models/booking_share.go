@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookingShare is the part of a split booking's total one person pays with
+// their own checkout session. The owner's share is a share too. A split
+// booking is confirmed once every share is paid.
+type BookingShare struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	BookingID uuid.UUID  `gorm:"type:uuid;not null;index" json:"booking_id"`
+	Booking   *Booking   `gorm:"foreignKey:BookingID" json:"booking,omitempty"`
+	Email     string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	UserID    *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"` // invitee's account, if any
+	Owner     bool       `gorm:"not null;default:false" json:"owner"`
+	Amount    float64    `gorm:"not null" json:"amount"`
+	Currency  string     `gorm:"type:varchar(3)" json:"currency"`
+	Status    string     `gorm:"type:varchar(20);not null" json:"status"` // invited, paid, cancelled, expired
+	PaidBy    *uuid.UUID `gorm:"type:uuid" json:"paid_by,omitempty"`      // user who opened the last checkout; the owner when covering
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (s *BookingShare) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
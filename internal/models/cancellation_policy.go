@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CancellationPolicy decides how much of a payment is refunded when a booking
+// is cancelled, depending on how long before the start it happens. A policy
+// without FieldID is the global default; a field's own policy takes
+// precedence. Without any policy cancellations are refunded in full.
+// NULLs never collide in the unique index on FieldID, so a partial unique
+// index (config.MigrateCancellationPolicyConstraints) keeps the global policy
+// single.
+type CancellationPolicy struct {
+	ID        uuid.UUID          `gorm:"type:uuid;primaryKey" json:"id"`
+	FieldID   *uuid.UUID         `gorm:"type:uuid;uniqueIndex" json:"field_id,omitempty"` // nil = global policy
+	Name      string             `gorm:"type:varchar(100);not null" json:"name"`
+	Tiers     []CancellationTier `gorm:"serializer:json" json:"tiers"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// CancellationTier refunds RefundPercent of the payment when the booking is
+// cancelled at least MinHoursBefore hours before it starts. The tier with the
+// highest matching MinHoursBefore applies; later cancellations get nothing.
+type CancellationTier struct {
+	MinHoursBefore float64 `json:"min_hours_before" example:"48"`
+	RefundPercent  float64 `json:"refund_percent" example:"100"`
+}
+
+func (p *CancellationPolicy) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return
+}
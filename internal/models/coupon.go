@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Coupon is a promo code taking a percentage or a fixed amount off a
+// checkout. A redemption is one checkout session using the code; sessions
+// that expire or fail do not count against the limits.
+type Coupon struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Code          string    `gorm:"type:varchar(32);not null;uniqueIndex" json:"code"` // stored upper case
+	Description   string    `json:"description"`
+	DiscountType  string    `gorm:"type:varchar(10);not null" json:"discount_type"` // percent, fixed
+	DiscountValue float64   `gorm:"not null" json:"discount_value"`                 // percent (0-100) or amount in Currency
+	Currency      string    `gorm:"type:varchar(3)" json:"currency,omitempty"`      // fixed discounts only
+
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+
+	MaxUses        *int        `json:"max_uses,omitempty"`               // nil = unlimited
+	MaxUsesPerUser *int        `json:"max_uses_per_user,omitempty"`      // nil = unlimited
+	FieldIDs       []uuid.UUID `gorm:"serializer:json" json:"field_ids"` // empty = all fields
+	Active         bool        `gorm:"not null" json:"active"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func (c *Coupon) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeSave stores times in UTC, see Booking.BeforeSave.
+func (c *Coupon) BeforeSave(tx *gorm.DB) (err error) {
+	if c.ValidFrom != nil {
+		t := c.ValidFrom.UTC()
+		c.ValidFrom = &t
+	}
+	if c.ValidUntil != nil {
+		t := c.ValidUntil.UTC()
+		c.ValidUntil = &t
+	}
+	return
+}
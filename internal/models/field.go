@@ -11,9 +11,16 @@ type Field struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
 	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
 	Location  string    `gorm:"type:varchar(255);not null" json:"location"`
-	Price     float64   `gorm:"not null" json:"price"`
+	Price     float64   `gorm:"not null" json:"price"` // price per hour
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Percentage of the booking total charged at checkout, the balance being
+	// paid later; 0 charges the full price
+	DepositPercent float64 `gorm:"not null;default:0" json:"deposit_percent"`
+
+	OpeningHours []FieldOpeningHour `gorm:"foreignKey:FieldID" json:"opening_hours,omitempty"`
+	Closures     []FieldClosure     `gorm:"foreignKey:FieldID" json:"closures,omitempty"`
 }
 
 // generate UUID otomatis sebelum create
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FieldOpeningHour is a single opening interval of a field on a weekday.
+// A field can have several intervals per weekday; a field without any
+// opening hours is treated as open around the clock.
+type FieldOpeningHour struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FieldID   uuid.UUID `gorm:"type:uuid;not null;index" json:"field_id"`
+	Weekday   int       `gorm:"not null" json:"weekday"`                    // 0 = Sunday ... 6 = Saturday
+	OpenTime  string    `gorm:"type:varchar(5);not null" json:"open_time"`  // HH:MM
+	CloseTime string    `gorm:"type:varchar(5);not null" json:"close_time"` // HH:MM, 24:00 = midnight
+}
+
+func (h *FieldOpeningHour) BeforeCreate(tx *gorm.DB) (err error) {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return
+}
+
+// FieldClosure marks a whole day (holiday, maintenance) on which a field is closed.
+type FieldClosure struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FieldID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_field_closures_field_date" json:"field_id"`
+	Date      string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_field_closures_field_date" json:"date"` // YYYY-MM-DD in APP_TIMEZONE
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (fc *FieldClosure) BeforeCreate(tx *gorm.DB) (err error) {
+	if fc.ID == uuid.Nil {
+		fc.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invoice numbers a paid payment. Numbers are sequential per calendar year
+// (INV-2024-000001) and the amounts are fixed when the invoice is issued, so
+// later tax changes do not alter it.
+type Invoice struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PaymentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"payment_id"`
+	Number    string    `gorm:"type:varchar(40);not null;uniqueIndex" json:"number"`
+	Year      int       `gorm:"not null" json:"year"`
+	Sequence  int       `gorm:"not null" json:"sequence"`
+	IssuedAt  time.Time `gorm:"not null" json:"issued_at"`
+
+	Currency  string  `gorm:"type:varchar(3)" json:"currency"`
+	Total     float64 `json:"total"` // amount charged, tax included
+	TaxName   string  `gorm:"type:varchar(20)" json:"tax_name"`
+	TaxRate   float64 `json:"tax_rate"` // percent
+	TaxAmount float64 `json:"tax_amount"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (i *Invoice) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return
+}
+
+// InvoiceCounter holds the last invoice sequence of a year.
+type InvoiceCounter struct {
+	Year int `gorm:"primaryKey;autoIncrement:false"`
+	Last int `gorm:"not null;default:0"`
+}
@@ -12,12 +12,38 @@ type Payment struct {
 	BookingID uuid.UUID `gorm:"type:uuid;not null" json:"booking_id"`
 	Booking   Booking   `gorm:"foreignKey:BookingID"`
 
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	Status      string    `json:"status"`        // pending, paid, failed
-	StripeRefID string    `json:"stripe_ref_id"` // session ID atau payment intent ID
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	Status         string  `json:"status"`          // pending, succeeded, failed, expired, refunded, disputed, charged_back
+	RefundedAmount float64 `json:"refunded_amount"` // partial refunds keep the payment succeeded
+	StripeRefID    string  `json:"stripe_ref_id"`   // session ID atau payment intent ID
+	Provider       string  `gorm:"type:varchar(20);default:stripe" json:"provider"`
+
+	// Part of RefundedAmount credited to the user's wallet rather than
+	// refunded by the provider
+	WalletRefundedAmount float64 `gorm:"not null;default:0" json:"wallet_refunded_amount"`
+
+	// Set when the checkout completes; charge and dispute webhooks refer to it
+	PaymentIntentID string `gorm:"index" json:"payment_intent_id,omitempty"`
+	FailureReason   string `json:"failure_reason,omitempty"`
+
+	// Promo code applied at checkout; Amount is what was charged after it
+	CouponID       *uuid.UUID `gorm:"type:uuid;index" json:"coupon_id,omitempty"`
+	PromoCode      string     `gorm:"type:varchar(32)" json:"promo_code,omitempty"`
+	DiscountAmount float64    `json:"discount_amount"`
+
+	// deposit or balance of a booking paid in two steps; empty when paid in full
+	Purpose string `gorm:"type:varchar(20)" json:"purpose,omitempty"`
+
+	// Share of a split booking this payment is for
+	ShareID *uuid.UUID `gorm:"type:uuid;index" json:"share_id,omitempty"`
+
+	// Cancellation policy tier applied when the booking was cancelled
+	CancellationPolicyID *uuid.UUID `gorm:"type:uuid" json:"cancellation_policy_id,omitempty"`
+	RefundPercent        *float64   `json:"refund_percent,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (p *Payment) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PricingRule overrides a field's hourly price for matching hours, e.g.
+// evenings or weekends. When several rules match an hour, the one with the
+// highest priority wins; hours without a matching rule use Field.Price.
+type PricingRule struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FieldID      uuid.UUID `gorm:"type:uuid;not null;index" json:"field_id"`
+	Name         string    `gorm:"type:varchar(100);not null" json:"name"`
+	PricePerHour float64   `gorm:"not null" json:"price_per_hour"`
+	Weekdays     []int     `gorm:"serializer:json" json:"weekdays"`              // 0 = Sunday ... 6 = Saturday, empty = every day
+	StartTime    string    `gorm:"type:varchar(5)" json:"start_time,omitempty"`  // HH:MM, empty = from midnight
+	EndTime      string    `gorm:"type:varchar(5)" json:"end_time,omitempty"`    // HH:MM, empty = until midnight
+	StartDate    string    `gorm:"type:varchar(10)" json:"start_date,omitempty"` // YYYY-MM-DD inclusive, empty = no lower bound
+	EndDate      string    `gorm:"type:varchar(10)" json:"end_date,omitempty"`   // YYYY-MM-DD inclusive, empty = no upper bound
+	Priority     int       `gorm:"not null;default:0" json:"priority"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (r *PricingRule) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
@@ -15,6 +15,17 @@ type User struct {
 	Role      string    `gorm:"not null;default:user" json:"role"` // user or admin
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Set once the user follows the link emailed on registration; unverified
+	// users cannot book or pay
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// Two-factor authentication: the TOTP secret is set on setup and a code
+	// is required at login once enabled. TOTPLastStep is the time step of the
+	// last accepted code, so a code cannot be used twice.
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"mfa_enabled_at,omitempty"`
+	TOTPLastStep  int64      `json:"-"`
 }
 
 func (u User) BeforeCreate(tx *gorm.DB) (err error) {
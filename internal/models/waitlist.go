@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WaitlistEntry queues a user for a booked field/time window. When the window
+// becomes free the first waiting user is offered it as a pending booking
+// held for them (BookingID) until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID" json:"-"`
+
+	FieldID uuid.UUID `gorm:"type:uuid;not null;index" json:"field_id"`
+	Field   Field     `gorm:"foreignKey:FieldID" json:"field"`
+
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `gorm:"type:varchar(20);not null;default:waiting;index" json:"status"` // waiting, offered, booked, expired, cancelled
+
+	BookingID      *uuid.UUID `gorm:"type:uuid;index" json:"booking_id,omitempty"`
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeSave stores times in UTC, see Booking.BeforeSave.
+func (w *WaitlistEntry) BeforeSave(tx *gorm.DB) (err error) {
+	w.StartTime = w.StartTime.UTC()
+	w.EndTime = w.EndTime.UTC()
+	return
+}
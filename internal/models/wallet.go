@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wallet is a user's store credit. Balance is the sum of the user's wallet
+// ledger entries, kept on the row so it can be locked while it changes.
+type Wallet struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Currency  string    `gorm:"type:varchar(3);not null" json:"currency"`
+	Balance   float64   `gorm:"not null;default:0" json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WalletEntry is one leg of a double-entry wallet transaction. Every
+// transaction posts two entries with the same TransactionID that sum to zero:
+// one on the user's wallet account and one on a system account (top_ups,
+// bookings or refunds).
+type WalletEntry struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Account       string     `gorm:"type:varchar(20);not null;index" json:"account"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount        float64    `gorm:"not null" json:"amount"` // positive credits the account
+	Currency      string     `gorm:"type:varchar(3);not null" json:"currency"`
+	Kind          string     `gorm:"type:varchar(20);not null" json:"kind"` // top_up, payment, refund
+	PaymentID     *uuid.UUID `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	TopUpID       *uuid.UUID `gorm:"type:uuid" json:"top_up_id,omitempty"`
+	Description   string     `json:"description"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (e *WalletEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// WalletTopUp is money added to a wallet through a payment provider's
+// checkout. The wallet is credited when the checkout succeeds.
+type WalletTopUp struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount      float64   `gorm:"not null" json:"amount"`
+	Currency    string    `gorm:"type:varchar(3);not null" json:"currency"`
+	Status      string    `gorm:"type:varchar(20);not null" json:"status"` // pending, succeeded, expired, failed
+	StripeRefID string    `gorm:"index" json:"stripe_ref_id"`              // checkout session ID
+	Provider    string    `gorm:"type:varchar(20)" json:"provider"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *WalletTopUp) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEvent is a payment provider webhook delivery. Events are stored
+// before they are processed so retries of an already processed event are
+// skipped and failed ones can be replayed.
+type WebhookEvent struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Provider string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_webhook_events_provider_event" json:"provider"`
+	EventID  string    `gorm:"not null;uniqueIndex:idx_webhook_events_provider_event" json:"event_id"`
+	Type     string    `gorm:"index" json:"type"`
+	Payload  string    `gorm:"type:text" json:"payload"`
+
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	ProcessedAt *time.Time `gorm:"index" json:"processed_at,omitempty"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"` // last processing error
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (w *WebhookEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,283 @@
+// Package oidc signs users in with OpenID Connect providers such as Google,
+// using the authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown OIDC provider")
+	// ErrInvalidIDToken is returned for ID tokens with a bad signature,
+	// issuer, audience, nonce or expiry.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// Config configures a provider. Issuer is the issuer URL the discovery
+// document is read from; RedirectURL is the callback registered with it.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider is an OpenID Connect provider. Its discovery document and signing
+// keys are fetched on first use and cached.
+type Provider struct {
+	Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     keySet
+}
+
+// metadata is the part of the discovery document the login flow needs.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims are the verified claims of an ID token identifying the user.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// NewProvider creates a provider. Scopes default to openid, email and profile.
+func NewProvider(cfg Config) *Provider {
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{Config: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// AuthCodeURL is the authorization endpoint URL the user is sent to. The
+// code challenge is derived from verifier, which is sent again in Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return md.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Authenticate exchanges an authorization code for tokens and returns the
+// claims of the verified ID token, which must carry nonce.
+func (p *Provider) Authenticate(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	rawIDToken, err := p.exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+// exchange redeems an authorization code at the token endpoint and returns
+// the raw ID token.
+func (p *Provider) exchange(ctx context.Context, code, verifier string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {p.ClientID},
+	}
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc %s: token request: %w", p.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("oidc %s: token response (%s): %w", p.Name, resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("oidc %s: token request failed (%s): %s %s", p.Name, resp.Status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("oidc %s: token response has no id_token", p.Name)
+	}
+	return body.IDToken, nil
+}
+
+// discover fetches and caches the provider's discovery document.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var md metadata
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", &md); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(md.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("oidc %s: discovery issuer %q does not match %q", p.Name, md.Issuer, p.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc %s: incomplete discovery document", p.Name)
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc %s: GET %s: %w", p.Name, url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc %s: GET %s: %s", p.Name, url, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("oidc %s: GET %s: %w", p.Name, url, err)
+	}
+	return nil
+}
+
+// RandomString returns a random URL-safe string for states, nonces and
+// PKCE code verifiers.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge is the S256 PKCE code challenge of a code verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]*Provider{}
+)
+
+// Register makes a provider available under its name.
+func Register(p *Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name] = p
+}
+
+// Get returns the provider registered under name.
+func Get(name string) (*Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Init registers the providers configured in the environment: Google with
+// GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET, and a generic provider with
+// OIDC_ISSUER_URL, OIDC_CLIENT_ID and OIDC_CLIENT_SECRET, named by
+// OIDC_PROVIDER_NAME (default oidc). Callbacks are served under APP_BASE_URL.
+func Init() {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	callback := func(name string) string { return baseURL + "/api/v1/auth/oidc/" + name + "/callback" }
+
+	if id := os.Getenv("GOOGLE_CLIENT_ID"); id != "" {
+		Register(NewProvider(Config{
+			Name:         "google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     id,
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  callback("google"),
+		}))
+	}
+	if issuer := os.Getenv("OIDC_ISSUER_URL"); issuer != "" {
+		name := strings.ToLower(os.Getenv("OIDC_PROVIDER_NAME"))
+		if name == "" {
+			name = "oidc"
+		}
+		Register(NewProvider(Config{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  callback(name),
+			Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		}))
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(providers) == 0 {
+		log.Println("⚠️ No OIDC providers configured, social login disabled")
+		return
+	}
+	for name := range providers {
+		log.Printf("✅ OIDC provider: %s", name)
+	}
+}
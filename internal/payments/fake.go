@@ -0,0 +1,190 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider is an in-process provider for development and tests. Sessions
+// and refunds live in memory and nothing leaves the process: CompleteSession
+// simulates the customer paying and WebhookPayload builds the notification
+// the webhook endpoint accepts for it.
+type FakeProvider struct {
+	baseURL string
+
+	mu       sync.Mutex
+	sessions map[string]*fakeSession
+	refunds  map[string][]Refund // by session ID
+}
+
+type fakeSession struct {
+	CheckoutSession
+	currency string
+	amount   int64
+	refunded int64
+}
+
+func NewFakeProvider(baseURL string) *FakeProvider {
+	return &FakeProvider{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		sessions: map[string]*fakeSession{},
+		refunds:  map[string][]Refund{},
+	}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) CreateCheckoutSession(req CheckoutRequest) (*CheckoutSession, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("checkout session needs at least one item")
+	}
+	var amount int64
+	for _, item := range req.Items {
+		if item.Amount < 0 {
+			return nil, fmt.Errorf("invalid amount %d for %q", item.Amount, item.Name)
+		}
+		amount += item.Amount
+	}
+
+	id := "fake_cs_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	s := &fakeSession{
+		CheckoutSession: CheckoutSession{
+			ID:       id,
+			URL:      p.baseURL + "/api/v1/payments/fake/" + id + "/complete",
+			Status:   SessionOpen,
+			Metadata: req.Metadata,
+		},
+		currency: req.Currency,
+		amount:   amount,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sessions[id] = s
+	return s.snapshot(), nil
+}
+
+func (p *FakeProvider) GetCheckoutSession(id string) (*CheckoutSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", id)
+	}
+	return s.snapshot(), nil
+}
+
+func (p *FakeProvider) ExpireCheckoutSession(id string) (*CheckoutSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", id)
+	}
+	if s.Status == SessionOpen {
+		s.Status = SessionExpired
+	}
+	return s.snapshot(), nil
+}
+
+func (p *FakeProvider) Refund(sessionID string, amount int64, currency string) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", sessionID)
+	}
+	if s.Status != SessionComplete {
+		return nil, errors.New("checkout session has not been paid")
+	}
+	if !strings.EqualFold(currency, s.currency) {
+		return nil, fmt.Errorf("refund currency %s does not match payment currency %s", currency, s.currency)
+	}
+	if amount <= 0 || s.refunded+amount > s.amount {
+		return nil, fmt.Errorf("refund of %d exceeds the remaining %d", amount, s.amount-s.refunded)
+	}
+
+	s.refunded += amount
+	ref := Refund{ID: "fake_re_" + strings.ReplaceAll(uuid.NewString(), "-", ""), Status: "succeeded"}
+	p.refunds[sessionID] = append(p.refunds[sessionID], ref)
+	return &ref, nil
+}
+
+// fakeEvent is the webhook payload format of the fake provider, a subset of
+// Stripe's event object.
+type fakeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID string `json:"id"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// VerifyWebhook accepts events about sessions of this provider; events for
+// unknown sessions are rejected.
+func (p *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	var e fakeEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	s, err := p.GetCheckoutSession(e.Data.Object.ID)
+	if err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	return &Event{ID: e.ID, Type: e.Type, Session: s}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte) (*Event, error) {
+	var e fakeEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, err
+	}
+	return &Event{ID: e.ID, Type: e.Type, Session: &CheckoutSession{ID: e.Data.Object.ID}}, nil
+}
+
+// CompleteSession simulates the customer paying an open checkout session.
+func (p *FakeProvider) CompleteSession(id string) (*CheckoutSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", id)
+	}
+	if s.Status != SessionOpen {
+		return nil, fmt.Errorf("checkout session is %s", s.Status)
+	}
+	s.Status = SessionComplete
+	s.Paid = true
+	return s.snapshot(), nil
+}
+
+// Refunds lists the refunds issued for a checkout session.
+func (p *FakeProvider) Refunds(sessionID string) []Refund {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Refund(nil), p.refunds[sessionID]...)
+}
+
+// WebhookPayload builds the webhook body of an event about a session.
+func (p *FakeProvider) WebhookPayload(eventType, sessionID string) []byte {
+	var e fakeEvent
+	e.ID = "fake_evt_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	e.Type = eventType
+	e.Data.Object.ID = sessionID
+	payload, _ := json.Marshal(e)
+	return payload
+}
+
+func (s *fakeSession) snapshot() *CheckoutSession {
+	c := s.CheckoutSession
+	c.AmountRefunded = s.refunded
+	return &c
+}
@@ -0,0 +1,335 @@
+package payments
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MidtransProvider uses Midtrans Snap, which offers the payment methods common
+// in Indonesia (bank transfer/virtual account, QRIS, GoPay, ShopeePay, cards)
+// on one hosted page. Only IDR is supported. Snap transactions are created at
+// SnapURL, status, expiry and refunds go through the Core API at APIURL; both
+// can point to a local mock server.
+type MidtransProvider struct {
+	ServerKey string
+	SnapURL   string // e.g. https://app.sandbox.midtrans.com
+	APIURL    string // e.g. https://api.sandbox.midtrans.com
+	Client    *http.Client
+}
+
+func NewMidtransProvider(serverKey, snapURL, apiURL string) *MidtransProvider {
+	return &MidtransProvider{
+		ServerKey: serverKey,
+		SnapURL:   strings.TrimRight(snapURL, "/"),
+		APIURL:    strings.TrimRight(apiURL, "/"),
+		Client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (p *MidtransProvider) Name() string { return "midtrans" }
+
+type midtransItem struct {
+	ID       string `json:"id"`
+	Price    int64  `json:"price"`
+	Quantity int    `json:"quantity"`
+	Name     string `json:"name"`
+}
+
+// CreateCheckoutSession creates a Snap transaction. The session ID is the
+// order ID, which Midtrans echoes in notifications.
+func (p *MidtransProvider) CreateCheckoutSession(req CheckoutRequest) (*CheckoutSession, error) {
+	if !strings.EqualFold(req.Currency, "idr") {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	orderID := "BMF-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	var gross int64
+	items := make([]midtransItem, 0, len(req.Items))
+	for i, item := range req.Items {
+		price := idrFromMinor(item.Amount)
+		gross += price
+		items = append(items, midtransItem{
+			ID:       strconv.Itoa(i + 1),
+			Price:    price,
+			Quantity: 1,
+			// Midtrans rejects item names over 50 characters
+			Name: truncate(item.Name, 50),
+		})
+	}
+
+	body := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     orderID,
+			"gross_amount": gross,
+		},
+		"item_details": items,
+		"callbacks": map[string]string{
+			"finish": strings.ReplaceAll(req.SuccessURL, "{CHECKOUT_SESSION_ID}", orderID),
+		},
+	}
+	if ref := req.Metadata["booking_id"]; ref != "" {
+		body["custom_field1"] = ref
+	} else if ref := req.Metadata["series_id"]; ref != "" {
+		body["custom_field1"] = ref
+	}
+
+	var resp struct {
+		Token       string `json:"token"`
+		RedirectURL string `json:"redirect_url"`
+	}
+	if err := p.do(http.MethodPost, p.SnapURL+"/snap/v1/transactions", body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &CheckoutSession{
+		ID:       orderID,
+		URL:      resp.RedirectURL,
+		Status:   SessionOpen,
+		Metadata: req.Metadata,
+	}, nil
+}
+
+// midtransStatus is the transaction status returned by the Core API and sent
+// in HTTP notifications.
+type midtransStatus struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	OrderID           string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	GrossAmount       string `json:"gross_amount"`
+	RefundAmount      string `json:"refund_amount"`
+	PaymentType       string `json:"payment_type"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+func (p *MidtransProvider) GetCheckoutSession(id string) (*CheckoutSession, error) {
+	var st midtransStatus
+	err := p.do(http.MethodGet, p.APIURL+"/v2/"+id+"/status", nil, &st)
+	if isMidtransNotFound(err) {
+		// No payment method chosen on the Snap page yet
+		return &CheckoutSession{ID: id, Status: SessionOpen}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st.OrderID = id
+	return st.session(), nil
+}
+
+func (p *MidtransProvider) ExpireCheckoutSession(id string) (*CheckoutSession, error) {
+	var st midtransStatus
+	err := p.do(http.MethodPost, p.APIURL+"/v2/"+id+"/expire", nil, &st)
+	if isMidtransNotFound(err) {
+		// Nothing was started on the Snap page; the payment record is
+		// expired on our side and the token lapses on its own
+		return &CheckoutSession{ID: id, Status: SessionExpired}, nil
+	}
+	if err != nil {
+		// Already settled or expired - check which one
+		return p.GetCheckoutSession(id)
+	}
+	st.OrderID = id
+	return st.session(), nil
+}
+
+// Refund refunds a settled transaction. Midtrans supports API refunds for
+// cards, GoPay, ShopeePay and QRIS; bank transfers are rejected by Midtrans
+// and have to be refunded manually.
+func (p *MidtransProvider) Refund(sessionID string, amount int64, currency string) (*Refund, error) {
+	if !strings.EqualFold(currency, "idr") {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	refundKey := "RF-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	body := map[string]interface{}{
+		"refund_key": refundKey,
+		"amount":     idrFromMinor(amount),
+		"reason":     "Booking cancelled or changed",
+	}
+	var resp struct {
+		StatusCode string `json:"status_code"`
+		RefundKey  string `json:"refund_key"`
+	}
+	if err := p.do(http.MethodPost, p.APIURL+"/v2/"+sessionID+"/refund", body, &resp); err != nil {
+		return nil, err
+	}
+	return &Refund{ID: refundKey, Status: "succeeded"}, nil
+}
+
+// VerifyWebhook checks the signature_key of an HTTP notification:
+// SHA512(order_id + status_code + gross_amount + server key).
+func (p *MidtransProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	var n midtransStatus
+	if err := json.Unmarshal(payload, &n); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + n.GrossAmount + p.ServerKey))
+	expected := hex.EncodeToString(sum[:])
+	if n.OrderID == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(n.SignatureKey))) != 1 {
+		return nil, ErrInvalidWebhook
+	}
+	return n.event(), nil
+}
+
+func (p *MidtransProvider) ParseWebhook(payload []byte) (*Event, error) {
+	var n midtransStatus
+	if err := json.Unmarshal(payload, &n); err != nil {
+		return nil, err
+	}
+	return n.event(), nil
+}
+
+func (n *midtransStatus) event() *Event {
+	return &Event{
+		ID:      n.OrderID + ":" + n.TransactionStatus,
+		Type:    eventType(n.TransactionStatus, n.FraudStatus),
+		Session: n.session(),
+	}
+}
+
+func (n *midtransStatus) session() *CheckoutSession {
+	status := sessionStatus(n.TransactionStatus, n.FraudStatus)
+	s := &CheckoutSession{ID: n.OrderID, Status: status, Paid: status == SessionComplete}
+	if refunded, err := strconv.ParseFloat(n.RefundAmount, 64); err == nil {
+		s.AmountRefunded = int64(math.Round(refunded * 100))
+	}
+	return s
+}
+
+// sessionStatus maps a Midtrans transaction status to a session status.
+func sessionStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement":
+		return SessionComplete
+	case "capture":
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return SessionComplete
+		}
+		return SessionOpen // challenged, waiting for review
+	case "expire", "cancel", "deny", "failure":
+		return SessionExpired
+	case "refund", "partial_refund":
+		return SessionComplete
+	}
+	return SessionOpen
+}
+
+// eventType maps a notification's transaction status to an event type.
+// Statuses without a counterpart (pending, challenged captures, refunds) map
+// to "midtrans.<status>" and are ignored.
+func eventType(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement":
+		return EventCheckoutCompleted
+	case "capture":
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return EventCheckoutCompleted
+		}
+	case "expire", "cancel":
+		return EventCheckoutExpired
+	case "deny", "failure":
+		return EventAsyncPaymentFailed
+	}
+	return "midtrans." + transactionStatus
+}
+
+// midtransError is an API response with a non-2xx status_code.
+type midtransError struct {
+	StatusCode string
+	Message    string
+}
+
+func (e *midtransError) Error() string {
+	return fmt.Sprintf("midtrans: %s %s", e.StatusCode, e.Message)
+}
+
+func isMidtransNotFound(err error) bool {
+	var mErr *midtransError
+	return errors.As(err, &mErr) && mErr.StatusCode == "404"
+}
+
+// do sends an authenticated JSON request. Core API errors come back as HTTP
+// 200 with an error status_code in the body, Snap errors as HTTP errors.
+// Status code 407 reports an expired transaction and is not an error.
+func (p *MidtransProvider) do(method, url string, body, out interface{}) error {
+	if p.ServerKey == "" {
+		return errors.New("midtrans: MIDTRANS_SERVER_KEY is not configured")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.ServerKey, "")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("midtrans: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("midtrans: %w", err)
+	}
+
+	var status struct {
+		StatusCode    string   `json:"status_code"`
+		StatusMessage string   `json:"status_message"`
+		ErrorMessages []string `json:"error_messages"`
+	}
+	_ = json.Unmarshal(raw, &status)
+	if resp.StatusCode >= 300 {
+		msg := strings.Join(status.ErrorMessages, "; ")
+		if msg == "" {
+			msg = status.StatusMessage
+		}
+		return &midtransError{StatusCode: strconv.Itoa(resp.StatusCode), Message: msg}
+	}
+	if status.StatusCode != "" && !strings.HasPrefix(status.StatusCode, "2") && status.StatusCode != "407" {
+		return &midtransError{StatusCode: status.StatusCode, Message: status.StatusMessage}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// idrFromMinor converts an amount in minor units (IDR is a two-decimal
+// currency in ToMinorUnits) to whole rupiah, the unit Midtrans expects.
+func idrFromMinor(amount int64) int64 {
+	return (amount + 50) / 100
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
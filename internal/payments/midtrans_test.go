@@ -0,0 +1,288 @@
+package payments
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testServerKey = "SB-Mid-server-test"
+
+// midtransMock is a stand-in for the Snap and Core APIs. Transactions are
+// created open; settle moves one to settlement.
+type midtransMock struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	statuses map[string]string // order ID -> transaction status
+	gross    map[string]int64
+	refunded map[string]int64
+}
+
+func newMidtransMock(t *testing.T) (*midtransMock, *MidtransProvider) {
+	m := &midtransMock{t: t, statuses: map[string]string{}, gross: map[string]int64{}, refunded: map[string]int64{}}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return m, NewMidtransProvider(testServerKey, srv.URL, srv.URL)
+}
+
+func (m *midtransMock) settle(orderID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses[orderID] = "settlement"
+}
+
+func (m *midtransMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if key, _, ok := r.BasicAuth(); !ok || key != testServerKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_messages": []string{"Access denied"}})
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.Method == http.MethodPost && r.URL.Path == "/snap/v1/transactions" {
+		var body struct {
+			TransactionDetails struct {
+				OrderID     string `json:"order_id"`
+				GrossAmount int64  `json:"gross_amount"`
+			} `json:"transaction_details"`
+			ItemDetails []struct {
+				Price    int64 `json:"price"`
+				Quantity int   `json:"quantity"`
+			} `json:"item_details"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var sum int64
+		for _, item := range body.ItemDetails {
+			sum += item.Price * int64(item.Quantity)
+		}
+		if sum != body.TransactionDetails.GrossAmount {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_messages": []string{"transaction_details.gross_amount is not equal to the sum of item_details"}})
+			return
+		}
+		id := body.TransactionDetails.OrderID
+		m.statuses[id] = "open"
+		m.gross[id] = sum
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"token": "snap-token", "redirect_url": "https://snap.test/v4/redirection/snap-token"})
+		return
+	}
+
+	// Core API: /v2/{order_id}/{action}, errors as HTTP 200 with status_code
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+	if len(parts) != 2 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id, action := parts[0], parts[1]
+	status, ok := m.statuses[id]
+	if !ok || status == "open" && action != "expire" {
+		// Midtrans only knows a transaction once a payment method is chosen
+		json.NewEncoder(w).Encode(map[string]string{"status_code": "404", "status_message": "Transaction doesn't exist."})
+		return
+	}
+
+	switch action {
+	case "status":
+		resp := map[string]string{
+			"status_code":        "200",
+			"order_id":           id,
+			"transaction_status": status,
+			"gross_amount":       fmt.Sprintf("%d.00", m.gross[id]),
+		}
+		if m.refunded[id] > 0 {
+			resp["refund_amount"] = fmt.Sprintf("%d.00", m.refunded[id])
+		}
+		json.NewEncoder(w).Encode(resp)
+	case "expire":
+		if status != "open" {
+			json.NewEncoder(w).Encode(map[string]string{"status_code": "412", "status_message": "Merchant cannot modify the status of the transaction"})
+			return
+		}
+		m.statuses[id] = "expire"
+		json.NewEncoder(w).Encode(map[string]string{"status_code": "407", "order_id": id, "transaction_status": "expire"})
+	case "refund":
+		var body struct {
+			RefundKey string `json:"refund_key"`
+			Amount    int64  `json:"amount"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if status != "settlement" && status != "partial_refund" || m.refunded[id]+body.Amount > m.gross[id] {
+			json.NewEncoder(w).Encode(map[string]string{"status_code": "412", "status_message": "Transaction cannot be refunded"})
+			return
+		}
+		m.refunded[id] += body.Amount
+		m.statuses[id] = "partial_refund"
+		json.NewEncoder(w).Encode(map[string]string{"status_code": "200", "refund_key": body.RefundKey})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMidtransCheckoutStatusAndRefund(t *testing.T) {
+	mock, p := newMidtransMock(t)
+
+	s, err := p.CreateCheckoutSession(CheckoutRequest{
+		Currency: "idr",
+		Items: []CheckoutItem{
+			{Name: "Lapangan Futsal A - Standard rate", Amount: 15000000},
+			{Name: "Lapangan Futsal A - Evening rate with a name longer than fifty characters", Amount: 5000000},
+		},
+		Metadata:   map[string]string{"booking_id": "b1"},
+		SuccessURL: "https://example.com/success?session_id={CHECKOUT_SESSION_ID}",
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckoutSession: %v", err)
+	}
+	if !strings.HasPrefix(s.ID, "BMF-") || s.URL == "" || s.Status != SessionOpen {
+		t.Fatalf("unexpected session %+v", s)
+	}
+	if mock.gross[s.ID] != 200000 {
+		t.Fatalf("gross amount %d, want 200000 rupiah", mock.gross[s.ID])
+	}
+
+	// Not started on the Snap page yet
+	got, err := p.GetCheckoutSession(s.ID)
+	if err != nil || got.Status != SessionOpen {
+		t.Fatalf("status before payment: %+v, %v", got, err)
+	}
+
+	mock.settle(s.ID)
+	got, err = p.GetCheckoutSession(s.ID)
+	if err != nil || got.Status != SessionComplete || !got.Paid {
+		t.Fatalf("status after settlement: %+v, %v", got, err)
+	}
+
+	ref, err := p.Refund(s.ID, 5000000, "idr")
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if !strings.HasPrefix(ref.ID, "RF-") {
+		t.Errorf("refund ID %q", ref.ID)
+	}
+	if mock.refunded[s.ID] != 50000 {
+		t.Errorf("mock refunded %d, want 50000 rupiah", mock.refunded[s.ID])
+	}
+	got, _ = p.GetCheckoutSession(s.ID)
+	if got.AmountRefunded != 5000000 {
+		t.Errorf("AmountRefunded %d, want 5000000", got.AmountRefunded)
+	}
+
+	if _, err := p.Refund(s.ID, 20000000, "idr"); err == nil {
+		t.Error("refund over the remaining amount succeeded")
+	}
+	if _, err := p.Refund(s.ID, 100, "usd"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("refund in usd: %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+func TestMidtransRejectsOtherCurrencies(t *testing.T) {
+	_, p := newMidtransMock(t)
+	_, err := p.CreateCheckoutSession(CheckoutRequest{Currency: "usd", Items: []CheckoutItem{{Name: "x", Amount: 100}}})
+	if !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Fatalf("got %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+func TestMidtransExpire(t *testing.T) {
+	mock, p := newMidtransMock(t)
+
+	// Never opened on Snap
+	s, err := p.ExpireCheckoutSession("BMF-unknown")
+	if err != nil || s.Status != SessionExpired {
+		t.Fatalf("expire unknown order: %+v, %v", s, err)
+	}
+
+	open, err := p.CreateCheckoutSession(CheckoutRequest{Currency: "idr", Items: []CheckoutItem{{Name: "x", Amount: 1000000}}})
+	if err != nil {
+		t.Fatalf("CreateCheckoutSession: %v", err)
+	}
+	s, err = p.ExpireCheckoutSession(open.ID)
+	if err != nil || s.Status != SessionExpired {
+		t.Fatalf("expire open order: %+v, %v", s, err)
+	}
+
+	paid, _ := p.CreateCheckoutSession(CheckoutRequest{Currency: "idr", Items: []CheckoutItem{{Name: "x", Amount: 1000000}}})
+	mock.settle(paid.ID)
+	s, err = p.ExpireCheckoutSession(paid.ID)
+	if err != nil || s.Status != SessionComplete {
+		t.Fatalf("expire settled order: %+v, %v, want it reported complete", s, err)
+	}
+}
+
+func TestMidtransWrongServerKey(t *testing.T) {
+	_, p := newMidtransMock(t)
+	p.ServerKey = "SB-Mid-server-wrong"
+	_, err := p.CreateCheckoutSession(CheckoutRequest{Currency: "idr", Items: []CheckoutItem{{Name: "x", Amount: 1000000}}})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("got %v, want a 401 error", err)
+	}
+}
+
+func midtransNotification(orderID, statusCode, gross, transactionStatus, key string) []byte {
+	sum := sha512.Sum512([]byte(orderID + statusCode + gross + key))
+	payload, _ := json.Marshal(map[string]string{
+		"order_id":           orderID,
+		"status_code":        statusCode,
+		"gross_amount":       gross,
+		"transaction_status": transactionStatus,
+		"signature_key":      hex.EncodeToString(sum[:]),
+	})
+	return payload
+}
+
+func TestMidtransVerifyWebhook(t *testing.T) {
+	p := NewMidtransProvider(testServerKey, "", "")
+
+	tests := []struct {
+		name    string
+		payload []byte
+		want    string // event type, empty when rejected
+	}{
+		{"settlement", midtransNotification("BMF-1", "200", "150000.00", "settlement", testServerKey), EventCheckoutCompleted},
+		{"expire", midtransNotification("BMF-1", "407", "150000.00", "expire", testServerKey), EventCheckoutExpired},
+		{"deny", midtransNotification("BMF-1", "202", "150000.00", "deny", testServerKey), EventAsyncPaymentFailed},
+		{"pending is ignored", midtransNotification("BMF-1", "201", "150000.00", "pending", testServerKey), "midtrans.pending"},
+		{"signed with another key", midtransNotification("BMF-1", "200", "150000.00", "settlement", "SB-Mid-server-other"), ""},
+		{"not json", []byte("order_id=BMF-1"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := p.VerifyWebhook(tt.payload, nil)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidWebhook) {
+					t.Fatalf("got %v, want ErrInvalidWebhook", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyWebhook: %v", err)
+			}
+			if event.Type != tt.want || event.Session.ID != "BMF-1" {
+				t.Fatalf("event %s for %s, want %s for BMF-1", event.Type, event.Session.ID, tt.want)
+			}
+		})
+	}
+
+	// A tampered amount breaks the signature
+	var n map[string]string
+	json.Unmarshal(midtransNotification("BMF-1", "200", "150000.00", "settlement", testServerKey), &n)
+	n["gross_amount"] = "1.00"
+	tampered, _ := json.Marshal(n)
+	if _, err := p.VerifyWebhook(tampered, nil); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("tampered amount: got %v, want ErrInvalidWebhook", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Package payments abstracts the payment provider used for checkout sessions,
+// refunds and webhooks so booking flows do not depend on Stripe directly.
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Checkout session statuses, shared by all providers.
+const (
+	SessionOpen     = "open"
+	SessionComplete = "complete"
+	SessionExpired  = "expired"
+)
+
+// Webhook event types. Providers translate their notifications to these,
+// which follow Stripe's naming.
+const (
+	EventCheckoutCompleted     = "checkout.session.completed"
+	EventCheckoutExpired       = "checkout.session.expired"
+	EventAsyncPaymentSucceeded = "checkout.session.async_payment_succeeded"
+	EventAsyncPaymentFailed    = "checkout.session.async_payment_failed"
+	EventPaymentIntentFailed   = "payment_intent.payment_failed"
+	EventChargeRefunded        = "charge.refunded"
+	EventDisputeCreated        = "charge.dispute.created"
+	EventDisputeClosed         = "charge.dispute.closed"
+)
+
+// Dispute outcomes of EventDisputeClosed.
+const (
+	DisputeWon  = "won"
+	DisputeLost = "lost"
+)
+
+var (
+	ErrInvalidWebhook = errors.New("invalid webhook signature")
+	// ErrUnsupportedCurrency is returned by providers that cannot charge the
+	// requested currency.
+	ErrUnsupportedCurrency = errors.New("currency not supported by payment provider")
+)
+
+// Provider is a payment provider. Amounts are in the currency's smallest unit.
+type Provider interface {
+	// Name identifies the provider, e.g. "stripe"; it is stored on payments.
+	Name() string
+	CreateCheckoutSession(req CheckoutRequest) (*CheckoutSession, error)
+	GetCheckoutSession(id string) (*CheckoutSession, error)
+	// ExpireCheckoutSession makes an open session unpayable and returns its
+	// final state, which is complete if it was paid in the meantime.
+	ExpireCheckoutSession(id string) (*CheckoutSession, error)
+	// Refund refunds amount of the payment made through a checkout session.
+	Refund(sessionID string, amount int64, currency string) (*Refund, error)
+	// VerifyWebhook authenticates a webhook request and parses its event.
+	VerifyWebhook(payload []byte, header http.Header) (*Event, error)
+	// ParseWebhook parses a webhook payload that was verified before, e.g. a
+	// stored event being replayed after its signature has gone stale.
+	ParseWebhook(payload []byte) (*Event, error)
+}
+
+// CheckoutItem is one line item of a checkout session.
+type CheckoutItem struct {
+	Name   string
+	Amount int64
+}
+
+// CheckoutRequest describes a checkout session to create.
+type CheckoutRequest struct {
+	Currency   string
+	Items      []CheckoutItem
+	Metadata   map[string]string
+	SuccessURL string
+	CancelURL  string
+}
+
+// CheckoutSession is a hosted payment page of a provider.
+type CheckoutSession struct {
+	ID       string
+	URL      string
+	Status   string // open, complete, expired
+	Metadata map[string]string
+	// Paid is false for a completed session whose delayed payment method
+	// (e.g. bank debit) has not settled yet.
+	Paid            bool
+	PaymentIntentID string
+	// AmountRefunded is the total refunded so far. It is only filled in
+	// by GetCheckoutSession.
+	AmountRefunded int64
+}
+
+// Refund is a refund issued by a provider.
+type Refund struct {
+	ID     string
+	Status string
+}
+
+// Event is a verified webhook notification. Checkout events carry the
+// session; charge, dispute and payment intent events identify the payment by
+// PaymentIntentID.
+type Event struct {
+	ID      string
+	Type    string
+	Session *CheckoutSession
+
+	PaymentIntentID string
+	Currency        string
+	AmountRefunded  int64  // charge.refunded: total refunded so far
+	DisputeStatus   string // charge.dispute.*: won, lost, ...
+	FailureMessage  string // payment_intent.payment_failed
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+	// selectable are the providers customers may pick per checkout besides
+	// Default, see Selectable
+	selectable = map[string]bool{}
+
+	// Default is the provider used for new checkout sessions.
+	Default Provider
+)
+
+// Register makes a provider available under its name.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name, or Default when name is
+// empty (payments created before providers were recorded).
+func Get(name string) (Provider, error) {
+	if name == "" {
+		return Default, nil
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+	return p, nil
+}
+
+// Selectable returns the provider a customer picked for a checkout, or Default
+// when name is empty. Only Default and the real, configured providers allowed
+// by PAYMENT_PROVIDERS can be picked, so a checkout can never be opened with a
+// provider whose sessions would not survive a restart.
+func Selectable(name string) (Provider, error) {
+	if name == "" || (Default != nil && name == Default.Name()) {
+		return Default, nil
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if !selectable[name] {
+		return nil, fmt.Errorf("payment provider %q is not available", name)
+	}
+	return providers[name], nil
+}
+
+// Init registers the built-in providers and selects the default one from
+// PAYMENT_PROVIDER (stripe, midtrans or fake, default stripe). Midtrans is
+// only registered when MIDTRANS_SERVER_KEY is set. The fake provider accepts
+// unsigned webhooks, so it is only registered when it is the default.
+func Init() {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	name := strings.ToLower(os.Getenv("PAYMENT_PROVIDER"))
+	if name == "" {
+		name = "stripe"
+	}
+
+	Register(NewStripeProvider(os.Getenv("STRIPE_WEBHOOK_SECRET")))
+	if name == "fake" {
+		log.Println("⚠️ Fake payment provider enabled, do not use in production")
+		Register(NewFakeProvider(baseURL))
+	}
+	if key := os.Getenv("MIDTRANS_SERVER_KEY"); key != "" {
+		snapURL := os.Getenv("MIDTRANS_SNAP_URL")
+		if snapURL == "" {
+			snapURL = "https://app.sandbox.midtrans.com"
+		}
+		apiURL := os.Getenv("MIDTRANS_API_URL")
+		if apiURL == "" {
+			apiURL = "https://api.sandbox.midtrans.com"
+		}
+		Register(NewMidtransProvider(key, snapURL, apiURL))
+	}
+
+	p, err := Get(name)
+	if err != nil {
+		log.Printf("⚠️ %v, using stripe", err)
+		p, _ = Get("stripe")
+	}
+	Default = p
+	log.Printf("✅ Payment provider: %s", Default.Name())
+
+	initSelectable(os.Getenv("PAYMENT_PROVIDERS"), os.Getenv("STRIPE_SECRET_KEY") != "")
+}
+
+// initSelectable allows the comma-separated providers in list to be picked
+// per checkout, by default every configured real provider: stripe when its
+// secret key is set and midtrans when registered. The fake provider can never
+// be picked next to the default.
+func initSelectable(list string, stripeConfigured bool) {
+	var names []string
+	if strings.TrimSpace(list) == "" {
+		if stripeConfigured {
+			names = append(names, "stripe")
+		}
+		names = append(names, "midtrans")
+	} else {
+		names = strings.Split(list, ",")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	selectable = map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := providers[name]; !ok || name == "fake" {
+			if list != "" {
+				log.Printf("⚠️ PAYMENT_PROVIDERS: %s is not available for checkout", name)
+			}
+			continue
+		}
+		selectable[name] = true
+	}
+}
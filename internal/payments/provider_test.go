@@ -0,0 +1,62 @@
+package payments
+
+import "testing"
+
+// resetProviders empties the registry for the test and restores it after.
+func resetProviders(t *testing.T) {
+	mu.Lock()
+	prev, prevSelectable, prevDefault := providers, selectable, Default
+	providers, selectable = map[string]Provider{}, map[string]bool{}
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		providers, selectable, Default = prev, prevSelectable, prevDefault
+		mu.Unlock()
+	})
+}
+
+func TestInitRegistersFakeOnlyAsDefault(t *testing.T) {
+	resetProviders(t)
+	t.Setenv("PAYMENT_PROVIDER", "")
+	t.Setenv("MIDTRANS_SERVER_KEY", "")
+	Init()
+	if _, err := Get("fake"); err == nil {
+		t.Fatal("fake provider registered although stripe is the default")
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "fake")
+	Init()
+	if _, ok := Default.(*FakeProvider); !ok {
+		t.Fatalf("default provider is %s, want fake", Default.Name())
+	}
+}
+
+func TestSelectableAllowsOnlyConfiguredRealProviders(t *testing.T) {
+	resetProviders(t)
+	Register(NewStripeProvider(""))
+	Register(NewMidtransProvider("SB-Mid-server-test", "", ""))
+	Register(NewFakeProvider(""))
+	Default, _ = Get("stripe")
+
+	initSelectable("", false)
+	if _, err := Selectable("midtrans"); err != nil {
+		t.Errorf("midtrans: %v", err)
+	}
+	if p, err := Selectable(""); err != nil || p.Name() != "stripe" {
+		t.Errorf("default: %v, %v", p, err)
+	}
+	if _, err := Selectable("fake"); err == nil {
+		t.Error("fake provider selectable next to stripe")
+	}
+	if _, err := Selectable("paypal"); err == nil {
+		t.Error("unknown provider selectable")
+	}
+
+	initSelectable("stripe,fake", true)
+	if _, err := Selectable("midtrans"); err == nil {
+		t.Error("midtrans selectable although not listed")
+	}
+	if _, err := Selectable("fake"); err == nil {
+		t.Error("fake provider selectable when listed")
+	}
+}
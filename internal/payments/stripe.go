@@ -0,0 +1,182 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/checkout/session"
+	stripeRefund "github.com/stripe/stripe-go/v76/refund"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+// StripeProvider uses Stripe Checkout. The API key is stripe.Key, set by
+// config.InitStripe.
+type StripeProvider struct {
+	webhookSecret string
+}
+
+func NewStripeProvider(webhookSecret string) *StripeProvider {
+	return &StripeProvider{webhookSecret: webhookSecret}
+}
+
+func (p *StripeProvider) Name() string { return "stripe" }
+
+func (p *StripeProvider) CreateCheckoutSession(req CheckoutRequest) (*CheckoutSession, error) {
+	lineItems := make([]*stripe.CheckoutSessionLineItemParams, 0, len(req.Items))
+	for _, item := range req.Items {
+		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(req.Currency),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(item.Name),
+				},
+				UnitAmount: stripe.Int64(item.Amount),
+			},
+			Quantity: stripe.Int64(1),
+		})
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
+		Mode:               stripe.String("payment"),
+		LineItems:          lineItems,
+		SuccessURL:         stripe.String(req.SuccessURL),
+		CancelURL:          stripe.String(req.CancelURL),
+		Metadata:           req.Metadata,
+	}
+	s, err := session.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return fromStripeSession(s), nil
+}
+
+func (p *StripeProvider) GetCheckoutSession(id string) (*CheckoutSession, error) {
+	params := &stripe.CheckoutSessionParams{}
+	params.AddExpand("payment_intent.latest_charge")
+	s, err := session.Get(id, params)
+	if err != nil {
+		return nil, err
+	}
+	cs := fromStripeSession(s)
+	if s.PaymentIntent != nil && s.PaymentIntent.LatestCharge != nil {
+		cs.AmountRefunded = s.PaymentIntent.LatestCharge.AmountRefunded
+	}
+	return cs, nil
+}
+
+func (p *StripeProvider) ExpireCheckoutSession(id string) (*CheckoutSession, error) {
+	// Without an API key no session can have been created
+	if stripe.Key == "" {
+		return &CheckoutSession{ID: id, Status: SessionExpired}, nil
+	}
+	s, err := session.Expire(id, nil)
+	if err != nil {
+		// Already completed or expired on Stripe's side - check which one
+		return p.GetCheckoutSession(id)
+	}
+	return fromStripeSession(s), nil
+}
+
+func (p *StripeProvider) Refund(sessionID string, amount int64, currency string) (*Refund, error) {
+	// Need the PaymentIntent ID from the checkout session
+	s, err := session.Get(sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.PaymentIntent == nil {
+		return nil, errors.New("payment intent not found")
+	}
+
+	ref, err := stripeRefund.New(&stripe.RefundParams{
+		PaymentIntent: stripe.String(s.PaymentIntent.ID),
+		Amount:        stripe.Int64(amount),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Refund{ID: ref.ID, Status: string(ref.Status)}, nil
+}
+
+func (p *StripeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	event, err := webhook.ConstructEvent(payload, header.Get("Stripe-Signature"), p.webhookSecret)
+	if err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	return parseStripeEvent(event)
+}
+
+func (p *StripeProvider) ParseWebhook(payload []byte) (*Event, error) {
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return parseStripeEvent(event)
+}
+
+func parseStripeEvent(event stripe.Event) (*Event, error) {
+	e := &Event{ID: event.ID, Type: string(event.Type)}
+	if event.Data == nil {
+		return e, nil
+	}
+
+	switch event.Data.Object["object"] {
+	case "checkout.session":
+		var s stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &s); err != nil {
+			return nil, err
+		}
+		e.Session = fromStripeSession(&s)
+	case "charge":
+		var ch stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &ch); err != nil {
+			return nil, err
+		}
+		if ch.PaymentIntent != nil {
+			e.PaymentIntentID = ch.PaymentIntent.ID
+		}
+		e.Currency = string(ch.Currency)
+		e.AmountRefunded = ch.AmountRefunded
+	case "dispute":
+		var d stripe.Dispute
+		if err := json.Unmarshal(event.Data.Raw, &d); err != nil {
+			return nil, err
+		}
+		if d.PaymentIntent != nil {
+			e.PaymentIntentID = d.PaymentIntent.ID
+		}
+		e.Currency = string(d.Currency)
+		e.DisputeStatus = string(d.Status)
+		// A closed inquiry without chargeback counts as won
+		if d.Status == stripe.DisputeStatusWarningClosed {
+			e.DisputeStatus = DisputeWon
+		}
+	case "payment_intent":
+		var pi stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &pi); err != nil {
+			return nil, err
+		}
+		e.PaymentIntentID = pi.ID
+		e.Currency = string(pi.Currency)
+		if pi.LastPaymentError != nil {
+			e.FailureMessage = pi.LastPaymentError.Msg
+		}
+	}
+	return e, nil
+}
+
+func fromStripeSession(s *stripe.CheckoutSession) *CheckoutSession {
+	cs := &CheckoutSession{
+		ID:       s.ID,
+		URL:      s.URL,
+		Status:   string(s.Status),
+		Metadata: s.Metadata,
+		Paid:     s.PaymentStatus != stripe.CheckoutSessionPaymentStatusUnpaid,
+	}
+	if s.PaymentIntent != nil {
+		cs.PaymentIntentID = s.PaymentIntent.ID
+	}
+	return cs
+}
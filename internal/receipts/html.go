@@ -0,0 +1,72 @@
+package receipts
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Receipt {{.InvoiceNumber}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 720px; margin: 40px auto; }
+  h1 { font-size: 22px; margin-bottom: 4px; }
+  .muted { color: #666; font-size: 13px; }
+  .parties { display: flex; justify-content: space-between; margin: 24px 0; }
+  table { width: 100%; border-collapse: collapse; margin-top: 16px; }
+  td, th { padding: 6px 0; text-align: left; }
+  td.amount, th.amount { text-align: right; }
+  tr.total td { border-top: 1px solid #222; font-weight: bold; }
+  tr.sep td { border-top: 1px solid #ccc; }
+</style>
+</head>
+<body>
+<h1>Receipt / Invoice</h1>
+<div class="muted">No. {{.InvoiceNumber}} &middot; Issued {{.IssuedAt.Format "02 Jan 2006"}}</div>
+
+<div class="parties">
+  <div>
+    <strong>{{.Seller.Name}}</strong><br>
+    {{with .Seller.Address}}{{.}}<br>{{end}}
+    {{with .Seller.TaxID}}Tax ID: {{.}}{{end}}
+  </div>
+  <div>
+    <strong>Billed to</strong><br>
+    {{.Customer.Name}}<br>
+    {{.Customer.Email}}
+  </div>
+</div>
+
+<div>
+  <strong>{{.Field}}</strong>{{with .Location}}, {{.}}{{end}}<br>
+  {{.Start.Format "Mon 02 Jan 2006 15:04"}} &ndash; {{.End.Format "15:04"}}
+</div>
+
+<table>
+  <tr><th>Description</th><th class="amount">Amount</th></tr>
+  {{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{$.Money .Amount}}</td></tr>
+  {{end}}
+  <tr class="sep"><td>Subtotal (excl. tax)</td><td class="amount">{{.Money .Subtotal}}</td></tr>
+  <tr><td>{{.TaxLabel}}</td><td class="amount">{{.Money .TaxAmount}}</td></tr>
+  <tr class="total"><td>Total paid</td><td class="amount">{{.Money .Total}}</td></tr>
+  {{if .Refunds}}
+  {{range .Refunds}}<tr><td>{{.Description}}</td><td class="amount">{{$.Money .Amount}}</td></tr>
+  {{end}}
+  <tr class="total"><td>Net paid</td><td class="amount">{{.Money .NetPaid}}</td></tr>
+  {{end}}
+</table>
+
+<p class="muted">
+  Payment {{.PaymentID}} via {{.Provider}} ({{.Status}})<br>
+  Reference {{.Reference}}
+</p>
+</body>
+</html>
+`))
+
+// WriteHTML renders the receipt as an HTML page.
+func WriteHTML(w io.Writer, r *Receipt) error {
+	return htmlTemplate.Execute(w, r)
+}
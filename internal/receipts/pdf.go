@@ -0,0 +1,97 @@
+package receipts
+
+import (
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// WritePDF renders the receipt as an A4 PDF using the built-in Helvetica
+// font; text is translated to cp1252.
+func WritePDF(w io.Writer, r *Receipt) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle("Receipt "+r.InvoiceNumber, true)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	const width = 170.0
+	half := width / 2
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(width, 9, "Receipt / Invoice", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(width, 5, tr("No. "+r.InvoiceNumber+"  ·  Issued "+r.IssuedAt.Format("02 Jan 2006")), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(6)
+
+	// Seller and customer side by side
+	y := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(half, 5, tr(r.Seller.Name), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	if r.Seller.Address != "" {
+		pdf.MultiCell(half, 5, tr(r.Seller.Address), "", "L", false)
+	}
+	if r.Seller.TaxID != "" {
+		pdf.CellFormat(half, 5, tr("Tax ID: "+r.Seller.TaxID), "", 2, "L", false, 0, "")
+	}
+	bottom := pdf.GetY()
+
+	pdf.SetXY(20+half, y)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(half, 5, "Billed to", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(half, 5, tr(r.Customer.Name), "", 2, "L", false, 0, "")
+	pdf.CellFormat(half, 5, tr(r.Customer.Email), "", 2, "L", false, 0, "")
+	if pdf.GetY() > bottom {
+		bottom = pdf.GetY()
+	}
+	pdf.SetXY(20, bottom+6)
+
+	field := r.Field
+	if r.Location != "" {
+		field += ", " + r.Location
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(width, 5, tr(field), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(width, 5, tr(r.Start.Format("Mon 02 Jan 2006 15:04")+" – "+r.End.Format("15:04")), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	amountWidth := 50.0
+	descWidth := width - amountWidth
+	row := func(desc, amount, border string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(descWidth, 7, tr(desc), border, 0, "L", false, 0, "")
+		pdf.CellFormat(amountWidth, 7, tr(amount), border, 1, "R", false, 0, "")
+	}
+
+	row("Description", "Amount", "B", true)
+	for _, l := range r.Lines {
+		row(l.Description, r.Money(l.Amount), "", false)
+	}
+	row("Subtotal (excl. tax)", r.Money(r.Subtotal()), "T", false)
+	row(r.TaxLabel(), r.Money(r.TaxAmount), "", false)
+	row("Total paid", r.Money(r.Total), "T", true)
+	if len(r.Refunds) > 0 {
+		for _, l := range r.Refunds {
+			row(l.Description, r.Money(l.Amount), "", false)
+		}
+		row("Net paid", r.Money(r.NetPaid), "T", true)
+	}
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(width, 4, tr("Payment "+r.PaymentID+" via "+r.Provider+" ("+r.Status+")"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(width, 4, tr("Reference "+r.Reference), "", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,98 @@
+// Package receipts renders payment receipts (invoices) as HTML and PDF.
+package receipts
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Party is the seller or the customer on a receipt.
+type Party struct {
+	Name    string
+	Address string
+	Email   string
+	TaxID   string
+}
+
+// Line is one amount on a receipt.
+type Line struct {
+	Description string
+	Amount      float64
+}
+
+// Receipt holds everything printed on a receipt. Times are in the local
+// timezone and amounts in Currency.
+type Receipt struct {
+	InvoiceNumber string
+	IssuedAt      time.Time
+	Seller        Party
+	Customer      Party
+
+	PaymentID string
+	Reference string // checkout session
+	Provider  string
+	Status    string
+
+	Field    string
+	Location string
+	Start    time.Time
+	End      time.Time
+
+	Currency string
+	Decimals int // 0 for zero-decimal currencies
+
+	Lines     []Line // charged items, discounts as negative amounts
+	Total     float64
+	TaxName   string
+	TaxRate   float64
+	TaxAmount float64
+	Refunds   []Line // negative amounts
+	NetPaid   float64
+}
+
+// Subtotal is the total without tax.
+func (r *Receipt) Subtotal() float64 {
+	return r.Total - r.TaxAmount
+}
+
+// TaxLabel describes the tax line, e.g. "PPN 11% (included)".
+func (r *Receipt) TaxLabel() string {
+	return fmt.Sprintf("%s %s%% (included)", r.TaxName, trimFloat(r.TaxRate))
+}
+
+// Money formats an amount with thousands separators, e.g. "IDR 400,000.00".
+func (r *Receipt) Money(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := fmt.Sprintf("%.*f", r.Decimals, amount)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteString("." + frac)
+	}
+	return fmt.Sprintf("%s%s %s", sign, strings.ToUpper(r.Currency), b.String())
+}
+
+// Filename is the download name of the receipt with the given extension.
+func (r *Receipt) Filename(ext string) string {
+	return fmt.Sprintf("%s.%s", r.InvoiceNumber, ext)
+}
+
+func trimFloat(f float64) string {
+	if f == math.Trunc(f) {
+		return fmt.Sprintf("%.0f", f)
+	}
+	return strings.TrimRight(fmt.Sprintf("%.2f", f), "0")
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
 )
 
 func AuthRoutes(api *gin.RouterGroup) {
@@ -12,4 +13,28 @@ func AuthRoutes(api *gin.RouterGroup) {
 	auth.POST("/login", controllers.Login)
 	auth.POST("/logout", controllers.Logout)
 	auth.POST("/refresh", controllers.Refresh)
+	auth.POST("/forgot-password", controllers.ForgotPassword)
+	auth.POST("/reset-password", controllers.ResetPassword)
+	auth.GET("/verify-email", controllers.VerifyEmail)
+	auth.POST("/verify-email/resend", middlewares.AuthMiddleware(), controllers.ResendVerificationEmail)
+	auth.GET("/oidc/:provider", controllers.OIDCLogin)
+	auth.GET("/oidc/:provider/callback", controllers.OIDCCallback)
+
+	sessions := auth.Group("/sessions", middlewares.AuthMiddleware())
+	{
+		sessions.GET("", controllers.GetSessions)
+		sessions.DELETE("", controllers.RevokeAllSessions)
+		sessions.DELETE("/:id", controllers.RevokeSession)
+	}
+
+	// Two-factor authentication; enrolling must work before the user has it
+	auth.POST("/mfa/verify", controllers.VerifyMFA)
+	mfa := auth.Group("/mfa", middlewares.AuthMiddlewareWithoutMFA())
+	{
+		mfa.GET("", controllers.GetMFAStatus)
+		mfa.POST("/setup", controllers.SetupMFA)
+		mfa.POST("/enable", controllers.EnableMFA)
+		mfa.POST("/disable", controllers.DisableMFA)
+		mfa.POST("/recovery-codes", controllers.RegenerateRecoveryCodes)
+	}
 }
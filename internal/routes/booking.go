@@ -11,10 +11,18 @@ func BookingsRoutes(api *gin.RouterGroup) {
 	booking := api.Group("/bookings")
 	booking.Use(middlewares.AuthMiddleware())
 	{
-		booking.GET("/", middlewares.AdminOnly(),controllers.GetBookings)     // semua booking (admin only)
-		booking.GET("/me", controllers.GetMyBookings) // hanya booking user sendiri
-		booking.POST("/", controllers.CreateBooking)
+		booking.GET("/", middlewares.AdminOnly(), controllers.GetBookings) // semua booking (admin only)
+		booking.GET("/me", controllers.GetMyBookings)                      // hanya booking user sendiri
+		booking.POST("/", middlewares.VerifiedEmailOnly(), controllers.CreateBooking)
+		booking.PATCH("/:id", controllers.RescheduleBooking)
 		booking.DELETE("/:id", controllers.CancelBooking)
 		booking.DELETE("/:id/cancel", controllers.CancelBooking)
+		booking.POST("/:id/mark-paid", middlewares.AdminOnly(), controllers.MarkBookingPaid)
+		booking.GET("/:id/shares", controllers.GetBookingShares)
+		booking.POST("/:id/shares", controllers.SplitBooking)
+		booking.DELETE("/:id/shares", controllers.CancelBookingSplit)
+		booking.POST("/:id/shares/cover", middlewares.VerifiedEmailOnly(), controllers.CoverBookingShares)
+		booking.GET("/series/:id", controllers.GetBookingSeries)
+		booking.DELETE("/series/:id", controllers.CancelBookingSeries)
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+)
+
+func CancellationPolicyRoutes(api *gin.RouterGroup) {
+	policy := api.Group("/cancellation-policy", middlewares.AuthMiddleware())
+	{
+		policy.GET("", controllers.GetGlobalCancellationPolicy)
+		policy.PUT("", middlewares.AdminOnly(), controllers.SetGlobalCancellationPolicy)
+		policy.DELETE("", middlewares.AdminOnly(), controllers.DeleteGlobalCancellationPolicy)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+)
+
+func CouponRoutes(rg *gin.RouterGroup) {
+	coupon := rg.Group("/coupons", middlewares.AuthMiddleware(), middlewares.AdminOnly())
+	{
+		coupon.GET("/", controllers.GetCoupons)
+		coupon.POST("/", controllers.CreateCoupon)
+		coupon.GET("/:id", controllers.GetCoupon)
+		coupon.PUT("/:id", controllers.UpdateCoupon)
+		coupon.DELETE("/:id", controllers.DeleteCoupon)
+	}
+}
@@ -11,6 +11,9 @@ func FieldRoutes(rg *gin.RouterGroup) {
 	{
 		field.GET("/", controllers.GetFields)
 		field.GET("/:id", controllers.GetFieldByID)
+		field.GET("/:id/availability", controllers.GetFieldAvailability)
+		field.GET("/:id/quote", controllers.GetFieldQuote)
+		field.GET("/:id/cancellation-policy", controllers.GetFieldCancellationPolicy)
 	}
 
 	admin := field.Group("/admin", middlewares.AdminOnly())
@@ -18,5 +21,14 @@ func FieldRoutes(rg *gin.RouterGroup) {
 		admin.POST("/", controllers.CreateField)
 		admin.DELETE("/:id", controllers.DeleteField)
 		admin.PUT("/:id", controllers.UpdateField)
+		admin.PUT("/:id/opening-hours", controllers.UpdateOpeningHours)
+		admin.POST("/:id/closures", controllers.CreateFieldClosure)
+		admin.DELETE("/:id/closures/:closure_id", controllers.DeleteFieldClosure)
+		admin.GET("/:id/pricing-rules", controllers.GetPricingRules)
+		admin.POST("/:id/pricing-rules", controllers.CreatePricingRule)
+		admin.PUT("/:id/pricing-rules/:rule_id", controllers.UpdatePricingRule)
+		admin.DELETE("/:id/pricing-rules/:rule_id", controllers.DeletePricingRule)
+		admin.PUT("/:id/cancellation-policy", controllers.SetFieldCancellationPolicy)
+		admin.DELETE("/:id/cancellation-policy", controllers.DeleteFieldCancellationPolicy)
 	}
 }
@@ -10,22 +10,32 @@ func PaymentRoutes(api *gin.RouterGroup) {
 	// Webhook endpoint (no authentication required - Di panggil di stripe)
 	// Harus di luar tanpa middalware auth
 	api.POST("/payments/stripe-webhook", controllers.StripeWebhook)
+	api.POST("/payments/webhooks/:provider", controllers.PaymentWebhook)
+	// HTTP notification Midtrans (signature_key diverifikasi)
+	api.POST("/payments/midtrans/notification", controllers.MidtransNotification)
 
-	// Test webhook endpoint untuk development (no signature validation)
-	api.POST("/payments/stripe-webhook-test", controllers.StripeWebhookTest)
+	// Simulasi pembayaran untuk fake provider (PAYMENT_PROVIDER=fake)
+	api.POST("/payments/fake/:id/complete", controllers.CompleteFakeCheckout)
 
 	payment := api.Group("/payments")
 	{
 		// Create checkout session (requires authentication)
-		payment.POST("/create-checkout-session", middlewares.AuthMiddleware(), controllers.CreateCheckoutSession)
+		payment.POST("/create-checkout-session", middlewares.AuthMiddleware(), middlewares.VerifiedEmailOnly(), controllers.CreateCheckoutSession)
 
 		// Get all payments (admin only)
 		payment.GET("/", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controllers.GetPayments)
 
+		// Received webhook events, replay of failed ones (admin only)
+		payment.GET("/webhook-events", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controllers.GetWebhookEvents)
+		payment.POST("/webhook-events/:id/replay", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controllers.ReplayWebhookEvent)
+
 		// Get user's payments (requires authentication)
 		payment.GET("/me", middlewares.AuthMiddleware(), controllers.GetMyPayments)
 
 		// Get payment by ID (requires authentication, user can only access own payments)
 		payment.GET("/:id", middlewares.AuthMiddleware(), controllers.GetPaymentByID)
+
+		// Receipt PDF/HTML of a paid payment (same access rules)
+		payment.GET("/:id/receipt", middlewares.AuthMiddleware(), controllers.GetPaymentReceipt)
 	}
 }
@@ -0,0 +1,383 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// testAPI serves the API routes exercised by the tests.
+type testAPI struct {
+	t      *testing.T
+	router *gin.Engine
+	db     *gorm.DB
+	fake   *payments.FakeProvider
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	BookingsRoutes(api)
+	PaymentRoutes(api)
+	ShareRoutes(api)
+	WalletRoutes(api)
+
+	return &testAPI{t: t, router: router, db: testutil.NewDB(t), fake: testutil.FakeProvider(t)}
+}
+
+// token signs an access token for the user.
+func (a *testAPI) token(user *models.User) string {
+	a.t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID.String(),
+		"role":    user.Role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(middlewares.JwtSecret)
+	if err != nil {
+		a.t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// do sends a request with a JSON body (nil for none), authenticated as user
+// unless user is nil, and decodes the JSON response into out unless out is nil.
+func (a *testAPI) do(method, path string, user *models.User, body interface{}, out interface{}) int {
+	a.t.Helper()
+
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	default:
+		var err error
+		if payload, err = json.Marshal(b); err != nil {
+			a.t.Fatalf("encode request: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req.Header.Set("Authorization", "Bearer "+a.token(user))
+	}
+
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if out != nil && w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			a.t.Fatalf("%s %s: decode response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+// book creates a pending booking of the field for an hour starting in two days
+// plus offset.
+func (a *testAPI) book(user *models.User, field *models.Field, offset time.Duration) models.Booking {
+	a.t.Helper()
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour).Add(offset)
+	var booking models.Booking
+	if code := a.do(http.MethodPost, "/api/v1/bookings/", user, gin.H{
+		"field_id":   field.ID.String(),
+		"start_time": start,
+		"end_time":   start.Add(time.Hour),
+	}, &booking); code != http.StatusCreated {
+		a.t.Fatalf("create booking: status %d", code)
+	}
+	return booking
+}
+
+// checkout opens a checkout session for the booking.
+func (a *testAPI) checkout(user *models.User, bookingID string) string {
+	a.t.Helper()
+
+	var resp struct {
+		SessionID  string `json:"session_id"`
+		SessionURL string `json:"session_url"`
+	}
+	if code := a.do(http.MethodPost, "/api/v1/payments/create-checkout-session", user, gin.H{"booking_id": bookingID}, &resp); code != http.StatusOK {
+		a.t.Fatalf("create checkout session: status %d", code)
+	}
+	if resp.SessionID == "" || resp.SessionURL == "" {
+		a.t.Fatalf("checkout session without ID or URL: %+v", resp)
+	}
+	return resp.SessionID
+}
+
+func (a *testAPI) bookingStatus(id interface{}) string {
+	a.t.Helper()
+
+	var booking models.Booking
+	if err := a.db.First(&booking, "id = ?", id).Error; err != nil {
+		a.t.Fatalf("load booking: %v", err)
+	}
+	return booking.Status
+}
+
+func (a *testAPI) sessionPayment(sessionID string) models.Payment {
+	a.t.Helper()
+
+	var payment models.Payment
+	if err := a.db.First(&payment, "stripe_ref_id = ?", sessionID).Error; err != nil {
+		a.t.Fatalf("load payment: %v", err)
+	}
+	return payment
+}
+
+func TestFakeCheckoutPayThenCancelRefunds(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 150000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+
+	if code := api.do(http.MethodPost, "/api/v1/payments/fake/"+sessionID+"/complete", nil, nil, nil); code != http.StatusOK {
+		t.Fatalf("complete fake checkout: status %d", code)
+	}
+	if status := api.bookingStatus(booking.ID); status != "confirmed" {
+		t.Fatalf("booking is %s after payment, want confirmed", status)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "succeeded" {
+		t.Fatalf("payment is %s after payment, want succeeded", p.Status)
+	}
+
+	var cancel struct {
+		RefundID       string  `json:"refund_id"`
+		RefundedAmount float64 `json:"refunded_amount"`
+	}
+	if code := api.do(http.MethodDelete, "/api/v1/bookings/"+booking.ID.String()+"/cancel", user, nil, &cancel); code != http.StatusOK {
+		t.Fatalf("cancel booking: status %d", code)
+	}
+	if cancel.RefundedAmount != 150000 || cancel.RefundID == "" {
+		t.Fatalf("cancel refunded %v (%q), want 150000", cancel.RefundedAmount, cancel.RefundID)
+	}
+	if refunds := api.fake.Refunds(sessionID); len(refunds) != 1 || refunds[0].ID != cancel.RefundID {
+		t.Fatalf("provider refunds %+v, want the one reported", refunds)
+	}
+	if status := api.bookingStatus(booking.ID); status != "cancelled" {
+		t.Fatalf("booking is %s after cancel, want cancelled", status)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "refunded" || p.RefundedAmount != 150000 {
+		t.Fatalf("payment is %s with %v refunded, want refunded in full", p.Status, p.RefundedAmount)
+	}
+}
+
+func TestCancelThenPayLateKeepsBookingCancelled(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	other := testutil.CreateUser(t, api.db, "other@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+	if code := api.do(http.MethodDelete, "/api/v1/bookings/"+booking.ID.String()+"/cancel", user, nil, nil); code != http.StatusOK {
+		t.Fatalf("cancel booking: status %d", code)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "expired" {
+		t.Fatalf("payment is %s after cancel, want expired", p.Status)
+	}
+
+	// Someone else books the released slot before the late payment
+	api.book(other, field, 0)
+
+	// The session left open in another tab can no longer be paid
+	if code := api.do(http.MethodPost, "/api/v1/payments/fake/"+sessionID+"/complete", nil, nil, nil); code != http.StatusBadRequest {
+		t.Fatalf("pay the cancelled booking's session: status %d, want 400", code)
+	}
+	payload := api.fake.WebhookPayload(payments.EventCheckoutCompleted, sessionID)
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, nil); code != http.StatusOK {
+		t.Fatalf("late webhook: status %d", code)
+	}
+	if status := api.bookingStatus(booking.ID); status != "cancelled" {
+		t.Fatalf("booking is %s after the late payment, want cancelled", status)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "expired" {
+		t.Fatalf("payment is %s after the late payment, want expired", p.Status)
+	}
+}
+
+func TestCancelAfterUnprocessedPaymentConflicts(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+
+	// Paid, but the webhook has not arrived yet
+	if _, err := api.fake.CompleteSession(sessionID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	if code := api.do(http.MethodDelete, "/api/v1/bookings/"+booking.ID.String()+"/cancel", user, nil, nil); code != http.StatusConflict {
+		t.Fatalf("cancel booking: status %d, want 409", code)
+	}
+	if status := api.bookingStatus(booking.ID); status != "pending" {
+		t.Fatalf("booking is %s, want it still pending", status)
+	}
+
+	// Once the payment is processed the cancellation refunds it
+	payload := api.fake.WebhookPayload(payments.EventCheckoutCompleted, sessionID)
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, nil); code != http.StatusOK {
+		t.Fatalf("webhook: status %d", code)
+	}
+	if code := api.do(http.MethodDelete, "/api/v1/bookings/"+booking.ID.String()+"/cancel", user, nil, nil); code != http.StatusOK {
+		t.Fatalf("cancel booking: status %d", code)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "refunded" {
+		t.Fatalf("payment is %s, want refunded", p.Status)
+	}
+}
+
+func TestFakeWebhookIsIdempotent(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+	if _, err := api.fake.CompleteSession(sessionID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+
+	payload := api.fake.WebhookPayload(payments.EventCheckoutCompleted, sessionID)
+	var first, second struct {
+		Status string `json:"status"`
+	}
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, &first); code != http.StatusOK || first.Status != "received" {
+		t.Fatalf("first delivery: status %d %q", code, first.Status)
+	}
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, &second); code != http.StatusOK || second.Status != "duplicate" {
+		t.Fatalf("second delivery: status %d %q, want duplicate", code, second.Status)
+	}
+
+	var invoices int64
+	api.db.Model(&models.Invoice{}).Count(&invoices)
+	if invoices != 1 {
+		t.Fatalf("%d invoices issued, want 1", invoices)
+	}
+	if status := api.bookingStatus(booking.ID); status != "confirmed" {
+		t.Fatalf("booking is %s, want confirmed", status)
+	}
+}
+
+func TestFakeWebhookExpiredSessionReleasesCheckout(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+	if _, err := api.fake.ExpireCheckoutSession(sessionID); err != nil {
+		t.Fatalf("expire session: %v", err)
+	}
+
+	payload := api.fake.WebhookPayload(payments.EventCheckoutExpired, sessionID)
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, nil); code != http.StatusOK {
+		t.Fatalf("expired webhook: status %d", code)
+	}
+	if p := api.sessionPayment(sessionID); p.Status != "expired" {
+		t.Fatalf("payment is %s, want expired", p.Status)
+	}
+
+	// The booking can be paid with a new session
+	if next := api.checkout(user, booking.ID.String()); next == sessionID {
+		t.Fatal("new checkout reused the expired session")
+	}
+}
+
+func TestFakeWebhookUnknownSessionRejected(t *testing.T) {
+	api := newTestAPI(t)
+
+	payload := api.fake.WebhookPayload(payments.EventCheckoutCompleted, "fake_cs_unknown")
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, nil); code != http.StatusBadRequest {
+		t.Fatalf("webhook for unknown session: status %d, want 400", code)
+	}
+}
+
+func TestFakeProviderDisabledUnlessDefault(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(user, field, 0)
+	sessionID := api.checkout(user, booking.ID.String())
+	if _, err := api.fake.CompleteSession(sessionID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+
+	// Registered, but a real provider is the default
+	payments.Default = payments.NewStripeProvider("whsec_test")
+
+	payload := api.fake.WebhookPayload(payments.EventCheckoutCompleted, sessionID)
+	if code := api.do(http.MethodPost, "/api/v1/payments/webhooks/fake", nil, payload, nil); code != http.StatusNotFound {
+		t.Fatalf("fake webhook: status %d, want 404", code)
+	}
+	if code := api.do(http.MethodPost, "/api/v1/payments/fake/"+sessionID+"/complete", nil, nil, nil); code != http.StatusNotFound {
+		t.Fatalf("fake checkout completion: status %d, want 404", code)
+	}
+	if status := api.bookingStatus(booking.ID); status != "pending" {
+		t.Fatalf("booking is %s, want it still pending", status)
+	}
+
+	// Nor can customers pick it for a checkout
+	second := api.book(user, field, 2*time.Hour)
+	if code := api.do(http.MethodPost, "/api/v1/payments/create-checkout-session", user, gin.H{
+		"booking_id": second.ID.String(),
+		"provider":   "fake",
+	}, nil); code != http.StatusBadRequest {
+		t.Fatalf("checkout with the fake provider: status %d, want 400", code)
+	}
+}
+
+func TestCheckoutWithPromoCodeRecordsSession(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+	coupon := models.Coupon{Code: "HEMAT10", DiscountType: "percent", DiscountValue: 10, Active: true}
+	if err := api.db.Create(&coupon).Error; err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+
+	booking := api.book(user, field, 0)
+	var resp struct {
+		SessionID      string  `json:"session_id"`
+		Amount         float64 `json:"amount"`
+		DiscountAmount float64 `json:"discount_amount"`
+	}
+	if code := api.do(http.MethodPost, "/api/v1/payments/create-checkout-session", user, gin.H{
+		"booking_id": booking.ID.String(),
+		"promo_code": "hemat10",
+	}, &resp); code != http.StatusOK {
+		t.Fatalf("create checkout session: status %d", code)
+	}
+	if resp.Amount != 90000 || resp.DiscountAmount != 10000 {
+		t.Fatalf("checkout charges %v with %v off, want 90000 with 10000 off", resp.Amount, resp.DiscountAmount)
+	}
+
+	// The payment recorded before the session was opened carries its ID
+	p := api.sessionPayment(resp.SessionID)
+	if p.Status != "pending" || p.CouponID == nil || *p.CouponID != coupon.ID {
+		t.Fatalf("payment is %s with coupon %v, want pending with %s", p.Status, p.CouponID, coupon.ID)
+	}
+	if s, err := api.fake.GetCheckoutSession(resp.SessionID); err != nil || s.Metadata["promo_code"] != "HEMAT10" {
+		t.Fatalf("provider session %+v (%v), want one with the promo code", s, err)
+	}
+}
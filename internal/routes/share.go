@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+)
+
+func ShareRoutes(rg *gin.RouterGroup) {
+	share := rg.Group("/shares", middlewares.AuthMiddleware())
+	{
+		share.GET("/me", middlewares.VerifiedEmailOnly(), controllers.GetMyShares)
+		share.POST("/:id/checkout", middlewares.VerifiedEmailOnly(), controllers.CreateShareCheckout)
+	}
+}
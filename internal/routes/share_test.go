@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestSplitBookingShares(t *testing.T) {
+	api := newTestAPI(t)
+	owner := testutil.CreateUser(t, api.db, "owner@example.com")
+	teammate := testutil.CreateUser(t, api.db, "teammate@example.com")
+	field := testutil.CreateField(t, api.db, 100000)
+
+	booking := api.book(owner, field, 0)
+	var shares []models.BookingShare
+	if code := api.do(http.MethodPost, "/api/v1/bookings/"+booking.ID.String()+"/shares", owner, gin.H{
+		"participants": []gin.H{{"email": teammate.Email}},
+	}, &shares); code != http.StatusCreated {
+		t.Fatalf("split booking: status %d", code)
+	}
+
+	// Participants get longer than the usual hold to pay
+	var split models.Booking
+	if err := api.db.First(&split, "id = ?", booking.ID).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	if want := time.Now().Add(config.SplitBookingHold); split.ExpiresAt == nil || split.ExpiresAt.Before(want.Add(-time.Minute)) {
+		t.Fatalf("split booking held until %v, want about %v", split.ExpiresAt, want)
+	}
+
+	// Shares are only shown and paid with a verified email
+	if err := api.db.Model(teammate).Update("email_verified_at", nil).Error; err != nil {
+		t.Fatalf("unverify user: %v", err)
+	}
+	if code := api.do(http.MethodGet, "/api/v1/shares/me", teammate, nil, nil); code != http.StatusForbidden {
+		t.Fatalf("shares of an unverified user: status %d, want 403", code)
+	}
+	var share models.BookingShare
+	if err := api.db.First(&share, "email = ?", teammate.Email).Error; err != nil {
+		t.Fatalf("load share: %v", err)
+	}
+	if code := api.do(http.MethodPost, "/api/v1/shares/"+share.ID.String()+"/checkout", teammate, nil, nil); code != http.StatusForbidden {
+		t.Fatalf("share checkout of an unverified user: status %d, want 403", code)
+	}
+
+	if err := api.db.Model(teammate).Update("email_verified_at", time.Now()).Error; err != nil {
+		t.Fatalf("verify user: %v", err)
+	}
+	var mine []models.BookingShare
+	if code := api.do(http.MethodGet, "/api/v1/shares/me", teammate, nil, &mine); code != http.StatusOK || len(mine) != 1 {
+		t.Fatalf("shares of a verified user: status %d, %d shares, want 1", code, len(mine))
+	}
+	var resp struct {
+		SessionID string `json:"session_id"`
+	}
+	if code := api.do(http.MethodPost, "/api/v1/shares/"+share.ID.String()+"/checkout", teammate, nil, &resp); code != http.StatusOK {
+		t.Fatalf("share checkout: status %d", code)
+	}
+	if p := api.sessionPayment(resp.SessionID); p.ShareID == nil || *p.ShareID != share.ID || p.Status != "pending" {
+		t.Fatalf("payment %+v, want a pending payment for the share", p)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+)
+
+func WaitlistRoutes(api *gin.RouterGroup) {
+	waitlist := api.Group("/waitlist", middlewares.AuthMiddleware())
+	{
+		waitlist.POST("/", controllers.JoinWaitlist)
+		waitlist.GET("/me", controllers.GetMyWaitlist)
+		waitlist.DELETE("/:id", controllers.LeaveWaitlist)
+	}
+}
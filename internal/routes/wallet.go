@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/controllers"
+	"github.com/qullDev/BookMyField/internal/middlewares"
+)
+
+func WalletRoutes(rg *gin.RouterGroup) {
+	wallet := rg.Group("/wallet", middlewares.AuthMiddleware())
+	{
+		wallet.GET("", controllers.GetMyWallet)
+		wallet.GET("/entries", controllers.GetMyWalletEntries)
+		wallet.POST("/top-ups", middlewares.VerifiedEmailOnly(), controllers.CreateWalletTopUp)
+	}
+}
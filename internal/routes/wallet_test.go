@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/services"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestUnsignedWebhookCannotCreditWallet(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+
+	stripe := payments.NewStripeProvider("whsec_test")
+	payments.Register(stripe)
+	payments.Default = stripe
+
+	topUp := models.WalletTopUp{UserID: user.ID, Amount: 500000, Currency: "IDR", Status: "pending",
+		StripeRefID: "cs_test_top_up", Provider: stripe.Name()}
+	if err := api.db.Create(&topUp).Error; err != nil {
+		t.Fatalf("create top-up: %v", err)
+	}
+
+	event := gin.H{
+		"id":   "evt_forged",
+		"type": payments.EventCheckoutCompleted,
+		"data": gin.H{"object": gin.H{
+			"id":             topUp.StripeRefID,
+			"payment_status": "paid",
+			"metadata":       gin.H{"wallet_top_up_id": topUp.ID.String()},
+		}},
+	}
+	for path, want := range map[string]int{
+		"/api/v1/payments/stripe-webhook-test": http.StatusNotFound,
+		"/api/v1/payments/stripe-webhook":      http.StatusBadRequest,
+		"/api/v1/payments/webhooks/stripe":     http.StatusBadRequest,
+	} {
+		if code := api.do(http.MethodPost, path, nil, event, nil); code != want {
+			t.Errorf("unsigned event to %s: status %d, want %d", path, code, want)
+		}
+	}
+
+	if err := api.db.First(&topUp, "id = ?", topUp.ID).Error; err != nil {
+		t.Fatalf("load top-up: %v", err)
+	}
+	if topUp.Status != "pending" {
+		t.Fatalf("top-up is %s, want pending", topUp.Status)
+	}
+	wallet, err := services.GetWallet(api.db, user.ID)
+	if err != nil {
+		t.Fatalf("load wallet: %v", err)
+	}
+	if wallet.Balance != 0 {
+		t.Fatalf("wallet balance %v, want 0", wallet.Balance)
+	}
+}
+
+func TestWalletTopUpRequiresVerifiedEmail(t *testing.T) {
+	api := newTestAPI(t)
+	user := testutil.CreateUser(t, api.db, "player@example.com")
+	if err := api.db.Model(user).Update("email_verified_at", nil).Error; err != nil {
+		t.Fatalf("unverify user: %v", err)
+	}
+
+	if code := api.do(http.MethodPost, "/api/v1/wallet/top-ups", user, gin.H{"amount": 200000}, nil); code != http.StatusForbidden {
+		t.Fatalf("top-up of an unverified user: status %d, want 403", code)
+	}
+	var topUps int64
+	api.db.Model(&models.WalletTopUp{}).Count(&topUps)
+	if topUps != 0 {
+		t.Fatalf("%d top-ups opened, want none", topUps)
+	}
+
+	if err := api.db.Model(user).Update("email_verified_at", time.Now()).Error; err != nil {
+		t.Fatalf("verify user: %v", err)
+	}
+	if code := api.do(http.MethodPost, "/api/v1/wallet/top-ups", user, gin.H{"amount": 200000}, nil); code != http.StatusOK {
+		t.Fatalf("top-up of a verified user: status %d, want 200", code)
+	}
+}
@@ -2,6 +2,7 @@ package seed
 
 import (
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
@@ -19,6 +20,7 @@ func SeedAdminUser() {
 
 	// hash password
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	now := time.Now()
 
 	admin := models.User{
 		ID:       uuid.New(),
@@ -26,6 +28,8 @@ func SeedAdminUser() {
 		Email:    "admin@admin.com",
 		Password: string(hashedPassword),
 		Role:     "admin",
+
+		EmailVerifiedAt: &now,
 	}
 
 	if err := config.DB.Create(&admin).Error; err != nil {
@@ -2,6 +2,7 @@ package seed
 
 import (
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/qullDev/BookMyField/internal/config"
@@ -17,6 +18,7 @@ func SeedRegularUser() {
 	}
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	now := time.Now()
 
 	regularUser := models.User{
 		ID:       uuid.New(),
@@ -24,6 +26,8 @@ func SeedRegularUser() {
 		Email:    "user@user.com",
 		Password: string(hashedPassword),
 		Role:     "user",
+
+		EmailVerifiedAt: &now,
 	}
 
 	if err := config.DB.Create(&regularUser).Error; err != nil {
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
+	"github.com/qullDev/BookMyField/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrEmailAlreadyVerified = errors.New("email address is already verified")
+
+// SendEmailVerification emails a user a link verifying their email address.
+// A new link revokes the previous one.
+func SendEmailVerification(db *gorm.DB, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	token, err := IssueUserToken(db, user.ID, TokenEmailVerification, config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nWelcome to BookMyField! Confirm your email address to start booking fields:\n\n%s\n\n"+
+		"The link expires in %s. If you did not sign up, ignore this email.\n",
+		user.Name, withToken(config.EmailVerificationURL, token), config.EmailVerificationTTL)
+	return mailer.Send(user.Email, "Verify your email address", body)
+}
+
+// VerifyEmail marks the email address of the user a verification token was
+// issued to as verified and uses up the token.
+func VerifyEmail(db *gorm.DB, token string) error {
+	userID, err := ConsumeUserToken(db, TokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+}
+
+// withToken appends token to link as the token query parameter.
+func withToken(link, token string) string {
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + sep + "token=" + url.QueryEscape(token)
+}
+
+// RequestPasswordReset emails a password reset token to the user with email,
+// if there is one. Unknown addresses are ignored so they cannot be probed.
+func RequestPasswordReset(db *gorm.DB, email string) error {
+	var user models.User
+	if err := db.First(&user, "email = ?", strings.ToLower(strings.TrimSpace(email))).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := IssueUserToken(db, user.ID, TokenPasswordReset, config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	howTo := "Reset it with this token (POST /api/v1/auth/reset-password):\n\n" + token
+	if config.PasswordResetURL != "" {
+		howTo = "Choose a new password here:\n\n" + withToken(config.PasswordResetURL, token)
+	}
+	body := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your BookMyField account.\n%s\n\n"+
+		"The link can be used once and expires in %s. If it was not you, ignore this email.\n",
+		user.Name, howTo, config.PasswordResetTTL)
+	if err := mailer.Send(user.Email, "Reset your BookMyField password", body); err != nil {
+		log.Printf("⚠️ Failed to send password reset email to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password for the user a password reset token was
+// issued to, uses up the token and signs the user out everywhere by revoking
+// their sessions.
+func ResetPassword(db *gorm.DB, token, password string) error {
+	userID, err := ConsumeUserToken(db, TokenPasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return ErrInvalidToken
+	}
+	if err := db.Model(&models.User{}).Where("id = ?", userID).Update("password", string(hashed)).Error; err != nil {
+		return err
+	}
+
+	if err := RevokeUserSessions(userID.String(), ""); err != nil {
+		log.Printf("⚠️ Failed to revoke sessions of user %s: %v", userID, err)
+	}
+	if err := mailer.Send(user.Email, "Your BookMyField password was changed",
+		fmt.Sprintf("Hi %s,\n\nThe password of your BookMyField account was just reset and you were signed out on all devices.\n"+
+			"If this was not you, reset your password again right away.\n", user.Name)); err != nil {
+		log.Printf("⚠️ Failed to send password change notice to %s: %v", user.Email, err)
+	}
+	return nil
+}
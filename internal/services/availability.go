@@ -0,0 +1,105 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+// Slot statuses returned by the availability endpoint
+const (
+	SlotAvailable = "available"
+	SlotBooked    = "booked"
+	SlotPast      = "past"
+	SlotClosed    = "closed"
+)
+
+// ActiveBookings returns the non-cancelled bookings of a field that overlap [from, to).
+func ActiveBookings(db *gorm.DB, fieldID uuid.UUID, from, to time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := db.
+		Where("field_id = ? AND status != ? AND start_time < ? AND end_time > ?",
+			fieldID, "cancelled", to.UTC(), from.UTC()).
+		Order("start_time").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// FieldAvailability builds the slot grid of a field for every day in [firstDay, lastDay].
+// Days are interpreted in config.Location and split into slots of the given length;
+// slots outside the field's opening hours or on closure days are reported as closed.
+func FieldAvailability(db *gorm.DB, fieldID uuid.UUID, firstDay, lastDay time.Time, slot time.Duration) ([]dto.DayAvailability, error) {
+	rangeStart := startOfDay(firstDay)
+	rangeEnd := startOfDay(lastDay).AddDate(0, 0, 1)
+
+	field, err := LoadFieldSchedule(db, fieldID, rangeStart, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	open := OpenIntervals(field, rangeStart, rangeEnd)
+
+	bookings, err := ActiveBookings(db, fieldID, rangeStart, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var days []dto.DayAvailability
+	for dayStart := rangeStart; dayStart.Before(rangeEnd); dayStart = dayStart.AddDate(0, 0, 1) {
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		day := dto.DayAvailability{
+			Date:  dayStart.Format("2006-01-02"),
+			Slots: []dto.AvailabilitySlot{},
+		}
+
+		for s := dayStart; s.Before(dayEnd); s = s.Add(slot) {
+			e := s.Add(slot)
+			if e.After(dayEnd) {
+				e = dayEnd
+			}
+
+			status := SlotAvailable
+			switch {
+			case overlapsBooking(bookings, s, e):
+				status = SlotBooked
+			case !withinIntervals(open, s, e):
+				status = SlotClosed
+			case s.Before(now):
+				status = SlotPast
+			}
+
+			day.Slots = append(day.Slots, dto.AvailabilitySlot{StartTime: s, EndTime: e, Status: status})
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func overlapsBooking(bookings []models.Booking, start, end time.Time) bool {
+	for _, b := range bookings {
+		if b.StartTime.Before(end) && b.EndTime.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinIntervals(intervals []Interval, start, end time.Time) bool {
+	for _, in := range intervals {
+		if in.Contains(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfDay returns local midnight (config.Location) of the day containing t.
+func startOfDay(t time.Time) time.Time {
+	t = t.In(config.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, config.Location)
+}
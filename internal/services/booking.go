@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrSlotTaken = errors.New("field is already booked for this time slot")
+
+// bookingMu serialises conflict check + insert on databases without the
+// bookings_no_overlap exclusion constraint (SQLite in development).
+var bookingMu sync.Mutex
+
+// HasConflict reports whether a non-cancelled booking of the field, other than
+// excludeID, overlaps [start, end).
+func HasConflict(db *gorm.DB, fieldID uuid.UUID, start, end time.Time, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.Booking{}).
+		Where("field_id = ? AND status != ? AND start_time < ? AND end_time > ? AND id != ?",
+			fieldID, "cancelled", end.UTC(), start.UTC(), excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateBooking inserts the booking if its time slot is still free and
+// returns ErrSlotTaken otherwise. The check and insert happen atomically.
+func CreateBooking(db *gorm.DB, booking *models.Booking) error {
+	return WithSlotLock(db, booking.FieldID, func(tx *gorm.DB) error {
+		conflict, err := HasConflict(tx, booking.FieldID, booking.StartTime, booking.EndTime, booking.ID)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return ErrSlotTaken
+		}
+		return tx.Create(booking).Error
+	})
+}
+
+// WithSlotLock runs fn in a transaction that holds the booking lock of a
+// field. On PostgreSQL this is a transaction-scoped advisory lock per field,
+// backed by the bookings_no_overlap exclusion constraint; elsewhere a
+// process-wide mutex is used. Exclusion violations are reported as ErrSlotTaken.
+func WithSlotLock(db *gorm.DB, fieldID uuid.UUID, fn func(tx *gorm.DB) error) error {
+	postgres := db.Dialector.Name() == "postgres"
+	if !postgres {
+		bookingMu.Lock()
+		defer bookingMu.Unlock()
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if postgres {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fieldID.String()).Error; err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+	if isExclusionViolation(err) {
+		return ErrSlotTaken
+	}
+	return err
+}
+
+// isExclusionViolation detects PostgreSQL's exclusion_violation (23P01).
+func isExclusionViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == "23P01"
+}
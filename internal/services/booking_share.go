@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidShares is wrapped by every reason a split cannot be made.
+var ErrInvalidShares = errors.New("invalid split")
+
+var (
+	ErrBookingSplit      = errors.New("booking is split between participants, pay it through its shares")
+	ErrNotSplittable     = errors.New("only pending bookings without payments can be split")
+	ErrSplitStarted      = errors.New("shares of this booking are already paid or being paid")
+	ErrBookingNotPayable = errors.New("booking is no longer awaiting payment")
+	ErrShareNotPayable   = errors.New("share is already paid, being paid or cancelled")
+)
+
+// ShareInvite is a participant to invite to a split booking.
+type ShareInvite struct {
+	Email  string
+	Amount float64 // 0 splits the total equally
+}
+
+// activeShareStatuses are the statuses of the shares of a booking that is
+// currently split.
+var activeShareStatuses = []string{"invited", "paid"}
+
+// IsSplit reports whether a booking is split into shares.
+func IsSplit(db *gorm.DB, bookingID uuid.UUID) (bool, error) {
+	var n int64
+	err := db.Model(&models.BookingShare{}).
+		Where("booking_id = ? AND status IN ?", bookingID, activeShareStatuses).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// SplitBooking splits the total of a pending, unpaid booking (with User and
+// Field loaded) between its owner and the invited participants. Invites
+// either all have an amount, the owner paying what is left, or none, the
+// total being split equally with the owner. The booking's hold is extended to
+// config.SplitBookingHold so participants have time to pay. Participants are
+// emailed their share.
+func SplitBooking(db *gorm.DB, booking *models.Booking, invites []ShareInvite) ([]models.BookingShare, error) {
+	currency := booking.Currency
+	total := ToMinorUnits(booking.TotalPrice, currency)
+
+	amounts := make([]int64, len(invites))
+	seen := map[string]bool{strings.ToLower(booking.User.Email): true}
+	withAmount := 0
+	var sum int64
+	for i, inv := range invites {
+		email := strings.ToLower(strings.TrimSpace(inv.Email))
+		if seen[email] {
+			return nil, fmt.Errorf("%w: %s is listed twice or is the owner", ErrInvalidShares, inv.Email)
+		}
+		seen[email] = true
+		invites[i].Email = email
+		amounts[i] = ToMinorUnits(inv.Amount, currency)
+		if amounts[i] > 0 {
+			withAmount++
+		}
+		sum += amounts[i]
+	}
+	switch withAmount {
+	case len(invites):
+	case 0:
+		each := total / int64(len(invites)+1)
+		for i := range amounts {
+			amounts[i] = each
+		}
+		sum = each * int64(len(invites))
+	default:
+		return nil, fmt.Errorf("%w: give every participant an amount or none", ErrInvalidShares)
+	}
+	if sum > total {
+		return nil, fmt.Errorf("%w: shares exceed the booking total", ErrInvalidShares)
+	}
+
+	shares := make([]models.BookingShare, 0, len(invites)+1)
+	if owner := total - sum; owner > 0 {
+		shares = append(shares, models.BookingShare{
+			BookingID: booking.ID,
+			Email:     strings.ToLower(booking.User.Email),
+			UserID:    &booking.UserID,
+			Owner:     true,
+			Amount:    FromMinorUnits(owner, currency),
+			Currency:  currency,
+			Status:    "invited",
+		})
+	}
+	for i, inv := range invites {
+		shares = append(shares, models.BookingShare{
+			BookingID: booking.ID,
+			Email:     inv.Email,
+			Amount:    FromMinorUnits(amounts[i], currency),
+			Currency:  currency,
+			Status:    "invited",
+		})
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Payments").First(&locked, "id = ?", booking.ID).Error; err != nil {
+			return err
+		}
+		if locked.Status != "pending" || (locked.ExpiresAt != nil && locked.ExpiresAt.Before(time.Now())) {
+			return ErrNotSplittable
+		}
+		for _, p := range locked.Payments {
+			if p.Status == "pending" || p.Status == "succeeded" {
+				return ErrNotSplittable
+			}
+		}
+		split, err := IsSplit(tx, booking.ID)
+		if err != nil {
+			return err
+		}
+		if split {
+			return ErrBookingSplit
+		}
+
+		holdUntil := time.Now().Add(config.SplitBookingHold)
+		if holdUntil.After(locked.StartTime) {
+			holdUntil = locked.StartTime
+		}
+		if locked.ExpiresAt == nil || holdUntil.After(*locked.ExpiresAt) {
+			if err := tx.Model(&locked).Update("expires_at", holdUntil).Error; err != nil {
+				return err
+			}
+			booking.ExpiresAt = &holdUntil
+		}
+
+		for i := range shares {
+			if shares[i].Owner {
+				continue
+			}
+			var user models.User
+			if err := tx.Select("id").Where("LOWER(email) = ?", shares[i].Email).First(&user).Error; err == nil {
+				shares[i].UserID = &user.ID
+			}
+		}
+		return tx.Create(&shares).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range shares {
+		if !s.Owner {
+			notifyShareInvite(booking, &s)
+		}
+	}
+	return shares, nil
+}
+
+// notifyShareInvite emails a participant their share of a booking.
+func notifyShareInvite(booking *models.Booking, share *models.BookingShare) {
+	start := booking.StartTime.In(config.Location)
+	deadline := "the booking's payment hold ends"
+	if booking.ExpiresAt != nil {
+		deadline = booking.ExpiresAt.In(config.Location).Format("02 Jan 15:04")
+	}
+	body := fmt.Sprintf("Hi,\n\n%s invited you to split the booking of %s on %s, %s-%s.\n"+
+		"Your share is %.2f %s. Sign in to BookMyField with this email address and pay share %s before %s;\n"+
+		"the booking is only confirmed once every share is paid.\n",
+		booking.User.Name, booking.Field.Name, start.Format("Mon 02 Jan 2006"), start.Format("15:04"),
+		booking.EndTime.In(config.Location).Format("15:04"), share.Amount, strings.ToUpper(share.Currency),
+		share.ID, deadline)
+	if err := mailer.Send(share.Email, "You have been invited to split a booking", body); err != nil {
+		log.Printf("⚠️ Failed to send share invite %s to %s: %v", share.ID, share.Email, err)
+	}
+}
+
+// CancelSplit cancels the shares of a booking nobody has paid or started
+// paying yet, so it can be paid in one checkout again.
+func CancelSplit(db *gorm.DB, bookingID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var started int64
+		if err := tx.Model(&models.Payment{}).
+			Where("booking_id = ? AND share_id IS NOT NULL AND status IN ?", bookingID, []string{"pending", "succeeded"}).
+			Count(&started).Error; err != nil {
+			return err
+		}
+		if started > 0 {
+			return ErrSplitStarted
+		}
+		return tx.Model(&models.BookingShare{}).
+			Where("booking_id = ? AND status IN ?", bookingID, activeShareStatuses).
+			Update("status", "cancelled").Error
+	})
+}
+
+// OpenShareCheckout records a pending payment per share of a booking (with
+// Field loaded) and then opens one checkout session with the provider for
+// them, see OpenReservedCheckout. payerID is recorded on the shares; wallet
+// refunds go to the payer.
+func OpenShareCheckout(db *gorm.DB, provider payments.Provider, booking *models.Booking, shares []models.BookingShare, payerID uuid.UUID) (*payments.CheckoutSession, float64, error) {
+	var (
+		items  []CheckoutItem
+		amount float64
+	)
+	ref := NewReservationRef()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", booking.ID).Error; err != nil {
+			return err
+		}
+		if locked.Status != "pending" || (locked.ExpiresAt != nil && locked.ExpiresAt.Before(time.Now())) {
+			return ErrBookingNotPayable
+		}
+
+		ids := make([]uuid.UUID, len(shares))
+		items = make([]CheckoutItem, len(shares))
+		start := booking.StartTime.In(config.Location).Format("02 Jan 2006 15:04")
+		for i, share := range shares {
+			if share.Status != "invited" {
+				return ErrShareNotPayable
+			}
+			ids[i] = share.ID
+			items[i] = CheckoutItem{
+				Name:   fmt.Sprintf("Share of %s - %s (%s)", booking.Field.Name, start, share.Email),
+				Amount: share.Amount,
+			}
+			amount += share.Amount
+		}
+		var open int64
+		if err := tx.Model(&models.Payment{}).
+			Where("share_id IN ? AND status IN ?", ids, []string{"pending", "succeeded"}).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrShareNotPayable
+		}
+
+		records := make([]models.Payment, len(shares))
+		for i, share := range shares {
+			records[i] = models.Payment{
+				BookingID:   booking.ID,
+				Amount:      share.Amount,
+				Currency:    booking.Currency,
+				Status:      "pending",
+				StripeRefID: ref,
+				Provider:    provider.Name(),
+				ShareID:     &ids[i],
+			}
+		}
+		if err := tx.Create(&records).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.BookingShare{}).Where("id IN ?", ids).Update("paid_by", payerID).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s, err := OpenReservedCheckout(db, provider, ref, booking.Currency, items, map[string]string{
+		"booking_id": booking.ID.String(),
+		"purpose":    "booking_share",
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, RoundAmount(amount, booking.Currency), nil
+}
+
+// markSharesPaid marks the shares paid by a checkout session as paid.
+func markSharesPaid(tx *gorm.DB, sessionID string) error {
+	paid := tx.Model(&models.Payment{}).Select("share_id").
+		Where("stripe_ref_id = ? AND share_id IS NOT NULL", sessionID)
+	return tx.Model(&models.BookingShare{}).
+		Where("id IN (?) AND status = ?", paid, "invited").
+		Update("status", "paid").Error
+}
+
+// refundShares refunds what was paid on the shares of a split booking whose
+// hold ran out before every share was paid.
+func refundShares(db *gorm.DB, booking *models.Booking) error {
+	for i := range booking.Payments {
+		p := &booking.Payments[i]
+		remaining := RoundAmount(p.Amount-p.RefundedAmount, p.Currency)
+		if p.Status != "succeeded" || remaining <= 0 {
+			continue
+		}
+		if _, err := RefundPayment(db, p, remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refundExpiredShares refunds the payments of a checkout session for shares
+// that expired while they were being paid.
+func refundExpiredShares(db *gorm.DB, sessionID string) error {
+	var late []models.Payment
+	if err := db.Where("stripe_ref_id = ? AND status = ?", sessionID, "succeeded").
+		Where("share_id IN (?)", db.Model(&models.BookingShare{}).Select("id").Where("status = ?", "expired")).
+		Find(&late).Error; err != nil {
+		return err
+	}
+	for i := range late {
+		if _, err := RefundPayment(db, &late[i], late[i].Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestCreateBookingConcurrentSameSlot(t *testing.T) {
+	db := testutil.NewDB(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	const attempts = 20
+
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			booking := &models.Booking{
+				UserID:    user.ID,
+				FieldID:   field.ID,
+				StartTime: start,
+				// every attempt overlaps the others by at least half an hour
+				EndTime: start.Add(time.Hour + time.Duration(i%3)*30*time.Minute),
+				Status:  "pending",
+			}
+			errs[i] = CreateBooking(db, booking)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrSlotTaken):
+		default:
+			t.Errorf("attempt %d: unexpected error %v", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d bookings succeeded, want exactly 1", succeeded)
+	}
+
+	var stored int64
+	db.Model(&models.Booking{}).Where("field_id = ?", field.ID).Count(&stored)
+	if stored != 1 {
+		t.Fatalf("%d bookings stored, want 1", stored)
+	}
+}
+
+func TestCreateBookingIgnoresCancelledAndAdjacent(t *testing.T) {
+	db := testutil.NewDB(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	cancelled := &models.Booking{UserID: user.ID, FieldID: field.ID, StartTime: start, EndTime: start.Add(time.Hour), Status: "cancelled"}
+	if err := db.Create(cancelled).Error; err != nil {
+		t.Fatalf("create cancelled booking: %v", err)
+	}
+
+	first := &models.Booking{UserID: user.ID, FieldID: field.ID, StartTime: start, EndTime: start.Add(time.Hour), Status: "pending"}
+	if err := CreateBooking(db, first); err != nil {
+		t.Fatalf("booking over a cancelled slot: %v", err)
+	}
+
+	adjacent := &models.Booking{UserID: user.ID, FieldID: field.ID, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour), Status: "pending"}
+	if err := CreateBooking(db, adjacent); err != nil {
+		t.Fatalf("booking the adjacent slot: %v", err)
+	}
+
+	overlap := &models.Booking{UserID: user.ID, FieldID: field.ID, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute), Status: "pending"}
+	if err := CreateBooking(db, overlap); !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("overlapping booking: got %v, want ErrSlotTaken", err)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+var ErrAlreadyCancelled = errors.New("booking already cancelled")
+
+// CancelResult describes the refund issued when cancelling a booking.
+type CancelResult struct {
+	Refunded       bool
+	ToWallet       bool
+	RefundID       string
+	RefundStatus   string
+	RefundedAmount float64
+	RefundPercent  float64
+	Policy         *models.CancellationPolicy // nil when no policy applies
+}
+
+// CancelBooking cancels a booking (with Payments preloaded). What is still
+// paid on its succeeded payments is refunded through their payment provider,
+// or as wallet credit with toWallet, according to the effective cancellation
+// policy, which is recorded on each payment, before the booking is
+// cancelled. Wallet and on-site payments are always refunded to the wallet.
+// Open checkout sessions are expired first and their pending payments marked
+// expired, so the booking cannot be paid once cancelled.
+func CancelBooking(db *gorm.DB, booking *models.Booking, toWallet bool) (*CancelResult, error) {
+	if booking.Status == "cancelled" {
+		return nil, ErrAlreadyCancelled
+	}
+	if err := expirePendingPayments(db, booking); err != nil {
+		return nil, err
+	}
+
+	policy, err := EffectiveCancellationPolicy(db, booking.FieldID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cancellation policy: %w", err)
+	}
+	percent := RefundPercent(policy, time.Until(booking.StartTime).Hours())
+
+	result := &CancelResult{RefundPercent: percent, Policy: policy}
+	for i := len(booking.Payments) - 1; i >= 0; i-- {
+		p := &booking.Payments[i]
+		remaining := p.Amount - p.RefundedAmount
+		if p.Status != "succeeded" || remaining <= 0 {
+			continue
+		}
+
+		// Refunds are recorded on the payment right away, so a failure
+		// halfway only leaves the remaining payments to refund on retry
+		if amount := RoundAmount(remaining*percent/100, p.Currency); amount > 0 {
+			refund := RefundPayment
+			if toWallet {
+				refund = RefundToWallet
+			}
+			ref, err := refund(db, p, amount)
+			if err != nil {
+				return nil, err
+			}
+			result.Refunded = true
+			result.ToWallet = result.ToWallet || toWallet || p.Provider == WalletProvider || p.Provider == OnSiteProvider
+			result.RefundID = ref.ID
+			result.RefundStatus = ref.Status
+			result.RefundedAmount = RoundAmount(result.RefundedAmount+amount, p.Currency)
+		}
+
+		if err := recordCancellationPolicy(db, p, policy, percent); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Model(booking).Update("status", "cancelled").Error; err != nil {
+		return nil, fmt.Errorf("failed to update booking status: %w", err)
+	}
+	if err := db.Model(&models.BookingShare{}).
+		Where("booking_id = ? AND status IN ?", booking.ID, activeShareStatuses).
+		Update("status", "cancelled").Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel booking shares: %w", err)
+	}
+	ReleaseSlot(db, booking.ID, booking.FieldID)
+
+	return result, nil
+}
+
+// expirePendingPayments expires the open checkout sessions of a booking (with
+// Payments preloaded) and marks their payments expired. It returns
+// ErrCheckoutCompleted if one was paid in the meantime, as the payment is
+// then settled by its webhook.
+func expirePendingPayments(db *gorm.DB, booking *models.Booking) error {
+	var expired []uuid.UUID
+	for i := range booking.Payments {
+		p := &booking.Payments[i]
+		if p.Status != "pending" {
+			continue
+		}
+		completed, err := ExpireCheckoutSession(p)
+		if err != nil {
+			return err
+		}
+		if completed {
+			return ErrCheckoutCompleted
+		}
+		expired = append(expired, p.ID)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	if err := db.Model(&models.Payment{}).
+		Where("id IN ? AND status = ?", expired, "pending").
+		Update("status", "expired").Error; err != nil {
+		return fmt.Errorf("failed to expire pending payments: %w", err)
+	}
+	for i := range booking.Payments {
+		if booking.Payments[i].Status == "pending" {
+			booking.Payments[i].Status = "expired"
+		}
+	}
+	return nil
+}
+
+// recordCancellationPolicy stores the policy and refund percentage applied
+// to a payment on cancellation.
+func recordCancellationPolicy(db *gorm.DB, payment *models.Payment, policy *models.CancellationPolicy, percent float64) error {
+	if policy != nil {
+		payment.CancellationPolicyID = &policy.ID
+	}
+	payment.RefundPercent = &percent
+	if err := db.Model(payment).Updates(map[string]interface{}{
+		"cancellation_policy_id": payment.CancellationPolicyID,
+		"refund_percent":         payment.RefundPercent,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	return nil
+}
+
+// RefundPayment refunds amount of a succeeded payment through its payment
+// provider and records it on the payment, which is marked refunded once
+// nothing is left.
+func RefundPayment(db *gorm.DB, payment *models.Payment, amount float64) (*payments.Refund, error) {
+	// What was paid from the wallet or at the venue goes back to the wallet
+	if payment.Provider == WalletProvider || payment.Provider == OnSiteProvider {
+		return RefundToWallet(db, payment, amount)
+	}
+	provider, err := payments.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := provider.Refund(payment.StripeRefID, ToMinorUnits(amount, payment.Currency), payment.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process refund: %w", err)
+	}
+
+	payment.RefundedAmount = RoundAmount(payment.RefundedAmount+amount, payment.Currency)
+	if payment.RefundedAmount >= payment.Amount {
+		payment.Status = "refunded"
+	}
+	if err := db.Model(payment).Updates(map[string]interface{}{
+		"refunded_amount": payment.RefundedAmount,
+		"status":          payment.Status,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	return ref, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+// ValidateCancellationPolicy checks the tiers of a policy and sorts them by
+// MinHoursBefore, latest cancellation last.
+func ValidateCancellationPolicy(policy *models.CancellationPolicy) error {
+	if len(policy.Tiers) == 0 {
+		return errors.New("a cancellation policy needs at least one tier")
+	}
+	seen := map[float64]bool{}
+	for _, t := range policy.Tiers {
+		if t.MinHoursBefore < 0 {
+			return errors.New("min_hours_before must not be negative")
+		}
+		if t.RefundPercent < 0 || t.RefundPercent > 100 {
+			return fmt.Errorf("invalid refund_percent %v, expected 0 to 100", t.RefundPercent)
+		}
+		if seen[t.MinHoursBefore] {
+			return fmt.Errorf("duplicate tier for min_hours_before %v", t.MinHoursBefore)
+		}
+		seen[t.MinHoursBefore] = true
+	}
+	sort.Slice(policy.Tiers, func(i, j int) bool {
+		return policy.Tiers[i].MinHoursBefore > policy.Tiers[j].MinHoursBefore
+	})
+	return nil
+}
+
+// SaveCancellationPolicy stores policy as its field's policy, or as the
+// global policy when FieldID is nil, replacing the name and tiers of an
+// existing one. One policy per field and one global policy are enforced by
+// unique indexes, so a concurrent save that creates the row first is turned
+// into an update instead of a duplicate.
+func SaveCancellationPolicy(db *gorm.DB, policy *models.CancellationPolicy) error {
+	for attempt := 0; ; attempt++ {
+		existing := func() *gorm.DB {
+			if policy.FieldID != nil {
+				return db.Where("field_id = ?", *policy.FieldID)
+			}
+			return db.Where("field_id IS NULL")
+		}
+
+		res := existing().Model(&models.CancellationPolicy{}).Select("name", "tiers").
+			Updates(&models.CancellationPolicy{Name: policy.Name, Tiers: policy.Tiers})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return existing().First(policy).Error
+		}
+
+		policy.ID = uuid.Nil
+		err := db.Create(policy).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+// EffectiveCancellationPolicy returns the field's cancellation policy, the
+// global policy when the field has none, or nil when neither exists.
+func EffectiveCancellationPolicy(db *gorm.DB, fieldID uuid.UUID) (*models.CancellationPolicy, error) {
+	var policies []models.CancellationPolicy
+	if err := db.Where("field_id = ? OR field_id IS NULL", fieldID).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	var global *models.CancellationPolicy
+	for i := range policies {
+		if policies[i].FieldID != nil {
+			return &policies[i], nil
+		}
+		global = &policies[i]
+	}
+	return global, nil
+}
+
+// RefundPercent returns the share of a payment refunded when cancelling
+// hoursBefore hours before the booking starts. Without a policy everything is
+// refunded.
+func RefundPercent(policy *models.CancellationPolicy, hoursBefore float64) float64 {
+	if policy == nil {
+		return 100
+	}
+	best := -1.0
+	percent := 0.0
+	for _, t := range policy.Tiers {
+		if hoursBefore >= t.MinHoursBefore && t.MinHoursBefore > best {
+			best, percent = t.MinHoursBefore, t.RefundPercent
+		}
+	}
+	return percent
+}
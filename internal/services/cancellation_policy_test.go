@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestSaveCancellationPolicyKeepsOneGlobalPolicy(t *testing.T) {
+	db := testutil.NewDB(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			policy := models.CancellationPolicy{
+				Name:  fmt.Sprintf("Policy %d", i),
+				Tiers: []models.CancellationTier{{MinHoursBefore: 24, RefundPercent: 50}},
+			}
+			errs[i] = SaveCancellationPolicy(db, &policy)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("save %d: %v", i, err)
+		}
+	}
+
+	var count int64
+	db.Model(&models.CancellationPolicy{}).Where("field_id IS NULL").Count(&count)
+	if count != 1 {
+		t.Fatalf("%d global policies stored, want 1", count)
+	}
+
+	// A direct insert of a second global policy is rejected by the database
+	if err := db.Create(&models.CancellationPolicy{Name: "Duplicate"}).Error; err == nil {
+		t.Fatal("second global policy was inserted")
+	}
+}
+
+func TestSaveCancellationPolicyReplacesFieldPolicy(t *testing.T) {
+	db := testutil.NewDB(t)
+	field := testutil.CreateField(t, db, 100000)
+
+	first := models.CancellationPolicy{
+		FieldID: &field.ID,
+		Name:    "Strict",
+		Tiers:   []models.CancellationTier{{MinHoursBefore: 48, RefundPercent: 100}},
+	}
+	if err := SaveCancellationPolicy(db, &first); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+	second := models.CancellationPolicy{
+		FieldID: &field.ID,
+		Name:    "Flexible",
+		Tiers:   []models.CancellationTier{{MinHoursBefore: 2, RefundPercent: 100}},
+	}
+	if err := SaveCancellationPolicy(db, &second); err != nil {
+		t.Fatalf("replace policy: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("replacement got a new ID %s, want %s", second.ID, first.ID)
+	}
+
+	policy, err := EffectiveCancellationPolicy(db, field.ID)
+	if err != nil {
+		t.Fatalf("EffectiveCancellationPolicy: %v", err)
+	}
+	if policy.Name != "Flexible" || len(policy.Tiers) != 1 || policy.Tiers[0].MinHoursBefore != 2 {
+		t.Errorf("effective policy %q %+v, want the replacement", policy.Name, policy.Tiers)
+	}
+	if !policy.UpdatedAt.After(policy.CreatedAt) {
+		t.Errorf("updated_at %v not after created_at %v", policy.UpdatedAt, policy.CreatedAt)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCoupon is wrapped by every reason a promo code cannot be used.
+var ErrInvalidCoupon = errors.New("invalid promo code")
+
+var (
+	ErrCouponNotFound    = fmt.Errorf("%w: not found", ErrInvalidCoupon)
+	ErrCouponInactive    = fmt.Errorf("%w: no longer active", ErrInvalidCoupon)
+	ErrCouponNotStarted  = fmt.Errorf("%w: not valid yet", ErrInvalidCoupon)
+	ErrCouponExpired     = fmt.Errorf("%w: expired", ErrInvalidCoupon)
+	ErrCouponField       = fmt.Errorf("%w: not valid for this field", ErrInvalidCoupon)
+	ErrCouponCurrency    = fmt.Errorf("%w: not valid for this currency", ErrInvalidCoupon)
+	ErrCouponUsedUp      = fmt.Errorf("%w: usage limit reached", ErrInvalidCoupon)
+	ErrCouponUserLimit   = fmt.Errorf("%w: you have already used it", ErrInvalidCoupon)
+	ErrCouponCoversTotal = fmt.Errorf("%w: discount cannot cover the whole amount", ErrInvalidCoupon)
+)
+
+// NormalizeCouponCode upper-cases and trims a promo code.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateCoupon checks a coupon's discount and validity window.
+func ValidateCoupon(c *models.Coupon) error {
+	switch c.DiscountType {
+	case "percent":
+		// A checkout always charges something, see ErrCouponCoversTotal
+		if c.DiscountValue <= 0 || c.DiscountValue >= 100 {
+			return errors.New("percent discounts must be above 0 and below 100")
+		}
+		c.Currency = ""
+	case "fixed":
+		if c.DiscountValue <= 0 {
+			return errors.New("fixed discounts must be positive")
+		}
+		if c.Currency == "" {
+			return errors.New("fixed discounts need a currency")
+		}
+	default:
+		return errors.New("discount_type must be percent or fixed")
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidUntil.After(*c.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	return nil
+}
+
+// ApplyCoupon looks up a promo code for a checkout by userID paying for
+// bookings (all in one currency) and returns the coupon with the discount on
+// each booking, in order. The coupon row is locked so concurrent checkouts
+// cannot exceed its usage limits; call it in the transaction that records the
+// payments.
+func ApplyCoupon(tx *gorm.DB, code string, userID uuid.UUID, bookings []models.Booking) (*models.Coupon, []float64, error) {
+	var coupon models.Coupon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", NormalizeCouponCode(code)).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCouponNotFound
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case !coupon.Active:
+		return nil, nil, ErrCouponInactive
+	case coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom):
+		return nil, nil, ErrCouponNotStarted
+	case coupon.ValidUntil != nil && !now.Before(*coupon.ValidUntil):
+		return nil, nil, ErrCouponExpired
+	}
+
+	currency := bookings[0].Currency
+	var subtotal float64
+	weights := make([]int64, len(bookings))
+	for i, b := range bookings {
+		if len(coupon.FieldIDs) > 0 && !slices.Contains(coupon.FieldIDs, b.FieldID) {
+			return nil, nil, ErrCouponField
+		}
+		subtotal += b.TotalPrice
+		weights[i] = ToMinorUnits(b.TotalPrice, currency)
+	}
+	if coupon.DiscountType == "fixed" && !strings.EqualFold(coupon.Currency, currency) {
+		return nil, nil, ErrCouponCurrency
+	}
+
+	if coupon.MaxUses != nil {
+		used, err := CouponUses(tx, coupon.ID, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if used >= int64(*coupon.MaxUses) {
+			return nil, nil, ErrCouponUsedUp
+		}
+	}
+	if coupon.MaxUsesPerUser != nil {
+		used, err := CouponUses(tx, coupon.ID, &userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if used >= int64(*coupon.MaxUsesPerUser) {
+			return nil, nil, ErrCouponUserLimit
+		}
+	}
+
+	discount := coupon.DiscountValue
+	if coupon.DiscountType == "percent" {
+		discount = subtotal * coupon.DiscountValue / 100
+	}
+	discount = RoundAmount(discount, currency)
+	if discount >= subtotal {
+		return nil, nil, ErrCouponCoversTotal
+	}
+
+	shares := SplitProportionally(ToMinorUnits(discount, currency), weights)
+	discounts := make([]float64, len(shares))
+	for i, s := range shares {
+		discounts[i] = FromMinorUnits(s, currency)
+	}
+	return &coupon, discounts, nil
+}
+
+// CouponUses counts the checkout sessions that used a coupon, optionally of
+// one user. Expired and failed ones do not count.
+func CouponUses(tx *gorm.DB, couponID uuid.UUID, userID *uuid.UUID) (int64, error) {
+	query := tx.Model(&models.Payment{}).
+		Where("payments.coupon_id = ? AND payments.status NOT IN ?", couponID, []string{"expired", "failed"})
+	if userID != nil {
+		query = query.Joins("JOIN bookings ON bookings.id = payments.booking_id").
+			Where("bookings.user_id = ?", *userID)
+	}
+	var n int64
+	err := query.Distinct("payments.stripe_ref_id").Count(&n).Error
+	return n, err
+}
+
+// DiscountItems takes discount off checkout line items in proportion to
+// their amounts and marks them with the promo code.
+func DiscountItems(items []CheckoutItem, discount float64, currency, code string) []CheckoutItem {
+	return ReduceItems(items, discount, currency, "promo "+code)
+}
+
+// ReduceItems takes amount off checkout line items in proportion to their
+// amounts and appends note to their names. Items reduced to nothing are
+// dropped.
+func ReduceItems(items []CheckoutItem, amount float64, currency, note string) []CheckoutItem {
+	weights := make([]int64, len(items))
+	for i, item := range items {
+		weights[i] = ToMinorUnits(item.Amount, currency)
+	}
+	shares := SplitProportionally(ToMinorUnits(amount, currency), weights)
+
+	reduced := make([]CheckoutItem, 0, len(items))
+	for i, item := range items {
+		if weights[i]-shares[i] <= 0 {
+			continue
+		}
+		reduced = append(reduced, CheckoutItem{
+			Name:   fmt.Sprintf("%s (%s)", item.Name, note),
+			Amount: FromMinorUnits(weights[i]-shares[i], currency),
+		})
+	}
+	return reduced
+}
+
+// SplitProportionally splits total over weights in proportion, in whole
+// units; rounding leftovers go to the last non-zero weight.
+func SplitProportionally(total int64, weights []int64) []int64 {
+	var sum int64
+	last := -1
+	for i, w := range weights {
+		sum += w
+		if w > 0 {
+			last = i
+		}
+	}
+	shares := make([]int64, len(weights))
+	if sum == 0 {
+		return shares
+	}
+
+	var assigned int64
+	for i, w := range weights {
+		if i == last {
+			shares[i] = total - assigned
+			break
+		}
+		shares[i] = total * w / sum
+		assigned += shares[i]
+	}
+	return shares
+}
+
+// Discounts sums the promo discounts of a booking's succeeded payments.
+func Discounts(payments []models.Payment) float64 {
+	var total float64
+	for _, p := range payments {
+		if p.Status == "succeeded" {
+			total += p.DiscountAmount
+		}
+	}
+	return total
+}
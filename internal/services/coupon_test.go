@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestValidateCouponPercentRange(t *testing.T) {
+	for _, tc := range []struct {
+		value float64
+		ok    bool
+	}{
+		{0, false},
+		{10, true},
+		{99.5, true},
+		{100, false},
+		{120, false},
+	} {
+		err := ValidateCoupon(&models.Coupon{Code: "PROMO", DiscountType: "percent", DiscountValue: tc.value})
+		if (err == nil) != tc.ok {
+			t.Errorf("%g%% discount: error %v, want ok %v", tc.value, err, tc.ok)
+		}
+	}
+}
+
+func TestOpenReservedCheckoutRecordsSession(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+
+	ref := NewReservationRef()
+	payment := models.Payment{ID: uuid.New(), BookingID: booking.ID, Amount: 100000, Currency: booking.Currency,
+		Status: "pending", StripeRefID: ref, Provider: fake.Name()}
+	if err := db.Create(&payment).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	s, err := OpenReservedCheckout(db, fake, ref, booking.Currency, []CheckoutItem{{Name: "Booking", Amount: 100000}}, nil)
+	if err != nil {
+		t.Fatalf("open checkout: %v", err)
+	}
+	if err := db.First(&payment, "id = ?", payment.ID).Error; err != nil {
+		t.Fatalf("load payment: %v", err)
+	}
+	if payment.StripeRefID != s.ID || payment.Status != "pending" {
+		t.Fatalf("payment is %s under %q, want pending under session %q", payment.Status, payment.StripeRefID, s.ID)
+	}
+}
+
+func TestOpenReservedCheckoutFailureReleasesCoupon(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+
+	coupon := models.Coupon{Code: "ONCE", DiscountType: "percent", DiscountValue: 10, Active: true}
+	if err := db.Create(&coupon).Error; err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+	ref := NewReservationRef()
+	payment := models.Payment{ID: uuid.New(), BookingID: booking.ID, Amount: 90000, Currency: booking.Currency,
+		Status: "pending", StripeRefID: ref, Provider: fake.Name(), CouponID: &coupon.ID, PromoCode: coupon.Code}
+	if err := db.Create(&payment).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	if uses, _ := CouponUses(db, coupon.ID, nil); uses != 1 {
+		t.Fatalf("%d coupon uses while reserved, want 1", uses)
+	}
+
+	// The fake provider refuses sessions without items
+	if _, err := OpenReservedCheckout(db, fake, ref, booking.Currency, nil, nil); err == nil {
+		t.Fatal("open checkout without items succeeded")
+	}
+	if err := db.First(&payment, "id = ?", payment.ID).Error; err != nil {
+		t.Fatalf("load payment: %v", err)
+	}
+	if payment.Status != "failed" {
+		t.Fatalf("payment is %s, want failed", payment.Status)
+	}
+	if uses, _ := CouponUses(db, coupon.ID, nil); uses != 0 {
+		t.Fatalf("%d coupon uses after the failed checkout, want 0", uses)
+	}
+}
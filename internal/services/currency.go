@@ -0,0 +1,41 @@
+package services
+
+import (
+	"math"
+	"strings"
+)
+
+// zeroDecimalCurrencies are charged in whole units by Stripe
+// (https://stripe.com/docs/currencies#zero-decimal).
+var zeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true,
+	"krw": true, "mga": true, "pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// IsZeroDecimal reports whether the currency has no minor unit.
+func IsZeroDecimal(currency string) bool {
+	return zeroDecimalCurrencies[strings.ToLower(currency)]
+}
+
+// ToMinorUnits converts an amount to the smallest currency unit, e.g.
+// 12.34 USD -> 1234 and 500 JPY -> 500.
+func ToMinorUnits(amount float64, currency string) int64 {
+	if IsZeroDecimal(currency) {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+// FromMinorUnits is the inverse of ToMinorUnits.
+func FromMinorUnits(amount int64, currency string) float64 {
+	if IsZeroDecimal(currency) {
+		return float64(amount)
+	}
+	return float64(amount) / 100
+}
+
+// RoundAmount rounds an amount to the precision of the currency.
+func RoundAmount(amount float64, currency string) float64 {
+	return FromMinorUnits(ToMinorUnits(amount, currency), currency)
+}
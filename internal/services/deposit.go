@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Purposes of the payments of a booking paid in two steps.
+const (
+	PurposeDeposit = "deposit"
+	PurposeBalance = "balance"
+)
+
+// OnSiteProvider is the provider name of balances collected at the venue and
+// marked paid by an admin. They are refunded as wallet credit.
+const OnSiteProvider = "on_site"
+
+var (
+	ErrNoBalanceDue      = errors.New("booking has no balance due")
+	ErrBalanceInProgress = errors.New("a checkout for the balance is already open")
+)
+
+// DepositDue is the part of due (in minor units) charged as deposit at the
+// given percentage.
+func DepositDue(due int64, percent float64) int64 {
+	if percent <= 0 || percent >= 100 {
+		return due
+	}
+	return int64(float64(due)*percent/100 + 0.5)
+}
+
+// BalanceDue is what is left to pay on a booking (with Payments loaded)
+// after its deposit. A promo discount counts as paid.
+func BalanceDue(booking *models.Booking) float64 {
+	due := booking.TotalPrice - PaidAmount(booking.Payments) - Discounts(booking.Payments)
+	return max(RoundAmount(due, booking.Currency), 0)
+}
+
+// MarkBalancePaid records the balance of a partially paid booking as
+// collected on site, confirms the booking and issues the invoice. Open
+// checkout sessions for the balance are expired first.
+func MarkBalancePaid(db *gorm.DB, booking *models.Booking) (*models.Payment, error) {
+	if booking.Status != "partially_paid" {
+		return nil, ErrNoBalanceDue
+	}
+	for i := range booking.Payments {
+		p := &booking.Payments[i]
+		if p.Status != "pending" {
+			continue
+		}
+		completed, err := ExpireCheckoutSession(p)
+		if err != nil {
+			return nil, err
+		}
+		if completed {
+			return nil, ErrCheckoutCompleted
+		}
+	}
+
+	var payment *models.Payment
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Payments").
+			First(&locked, "id = ?", booking.ID).Error; err != nil {
+			return err
+		}
+		if locked.Status != "partially_paid" {
+			return ErrNoBalanceDue
+		}
+		if err := tx.Model(&models.Payment{}).
+			Where("booking_id = ? AND status = ?", booking.ID, "pending").
+			Update("status", "expired").Error; err != nil {
+			return err
+		}
+
+		due := BalanceDue(&locked)
+		if due > 0 {
+			payment = &models.Payment{
+				BookingID: booking.ID,
+				Amount:    due,
+				Currency:  locked.Currency,
+				Status:    "succeeded",
+				Provider:  OnSiteProvider,
+				Purpose:   PurposeBalance,
+			}
+			if err := tx.Create(payment).Error; err != nil {
+				return err
+			}
+			if _, err := IssueInvoice(tx, payment); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Booking{}).Where("id = ?", booking.ID).Update("status", "confirmed").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	booking.Status = "confirmed"
+	return payment, nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// openPartCheckout opens a fake checkout session for part of a booking,
+// recording its pending payment with the given purpose.
+func openPartCheckout(t *testing.T, db *gorm.DB, fake *payments.FakeProvider, booking *models.Booking, amount float64, purpose string) *models.Payment {
+	t.Helper()
+
+	s, err := NewCheckoutSession(fake, booking.Currency, []CheckoutItem{{Name: "Booking " + purpose, Amount: amount}}, map[string]string{
+		"booking_id": booking.ID.String(),
+	})
+	if err != nil {
+		t.Fatalf("open checkout session: %v", err)
+	}
+	payment := &models.Payment{
+		BookingID:   booking.ID,
+		Amount:      amount,
+		Currency:    booking.Currency,
+		Status:      "pending",
+		StripeRefID: s.ID,
+		Provider:    fake.Name(),
+		Purpose:     purpose,
+	}
+	if err := db.Create(payment).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	return payment
+}
+
+// payTestCheckout completes the payment's session and applies it.
+func payTestCheckout(t *testing.T, db *gorm.DB, fake *payments.FakeProvider, payment *models.Payment) {
+	t.Helper()
+
+	if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+		t.Fatalf("mark checkout succeeded: %v", err)
+	}
+}
+
+// loadBookingWithPayments reloads a booking with its payments.
+func loadBookingWithPayments(t *testing.T, db *gorm.DB, booking *models.Booking) *models.Booking {
+	t.Helper()
+
+	var b models.Booking
+	if err := db.Preload("Payments").First(&b, "id = ?", booking.ID).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	return &b
+}
+
+// createDepositBooking books a field taking a 30% deposit.
+func createDepositBooking(t *testing.T, db *gorm.DB) *models.Booking {
+	t.Helper()
+
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	if err := db.Model(field).Update("deposit_percent", 30).Error; err != nil {
+		t.Fatalf("set deposit: %v", err)
+	}
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	return createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+}
+
+func TestDepositThenBalance(t *testing.T) {
+	for _, onSite := range []bool{false, true} {
+		db := testutil.NewDB(t)
+		fake := testutil.FakeProvider(t)
+		booking := createDepositBooking(t, db)
+
+		deposit := openPartCheckout(t, db, fake, booking, 30000, PurposeDeposit)
+		payTestCheckout(t, db, fake, deposit)
+
+		b := loadBookingWithPayments(t, db, booking)
+		if b.Status != "partially_paid" {
+			t.Fatalf("on site %v: booking is %s after the deposit, want partially_paid", onSite, b.Status)
+		}
+		if due := BalanceDue(b); due != 70000 {
+			t.Fatalf("on site %v: balance due %v, want 70000", onSite, due)
+		}
+
+		if onSite {
+			payment, err := MarkBalancePaid(db, b)
+			if err != nil {
+				t.Fatalf("mark balance paid: %v", err)
+			}
+			if payment.Amount != 70000 || payment.Provider != OnSiteProvider || payment.Status != "succeeded" {
+				t.Fatalf("balance payment %+v, want 70000 collected on site", payment)
+			}
+		} else {
+			balance := openPartCheckout(t, db, fake, b, 70000, PurposeBalance)
+			payTestCheckout(t, db, fake, balance)
+		}
+
+		b = loadBookingWithPayments(t, db, booking)
+		if b.Status != "confirmed" {
+			t.Fatalf("on site %v: booking is %s after the balance, want confirmed", onSite, b.Status)
+		}
+		if due := BalanceDue(b); due != 0 {
+			t.Fatalf("on site %v: balance due %v after paying it, want 0", onSite, due)
+		}
+		var invoices int64
+		db.Model(&models.Invoice{}).Count(&invoices)
+		if invoices != 2 {
+			t.Fatalf("on site %v: %d invoices, want one for the deposit and one for the balance", onSite, invoices)
+		}
+	}
+}
+
+func TestCancelPartiallyPaidBooking(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	booking := createDepositBooking(t, db)
+
+	deposit := openPartCheckout(t, db, fake, booking, 30000, PurposeDeposit)
+	payTestCheckout(t, db, fake, deposit)
+	// The customer opened a checkout for the balance but did not pay it
+	balance := openPartCheckout(t, db, fake, booking, 70000, PurposeBalance)
+
+	result, err := CancelBooking(db, loadBookingWithPayments(t, db, booking), false)
+	if err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	if !result.Refunded || result.RefundedAmount != 30000 {
+		t.Fatalf("refunded %v, want the 30000 deposit", result.RefundedAmount)
+	}
+
+	if b := loadBooking(t, db, booking.ID); b.Status != "cancelled" {
+		t.Fatalf("booking is %s, want cancelled", b.Status)
+	}
+	if p := loadPayment(t, db, deposit.ID); p.Status != "refunded" || p.RefundedAmount != 30000 {
+		t.Fatalf("deposit is %s with %v refunded, want refunded in full", p.Status, p.RefundedAmount)
+	}
+	if p := loadPayment(t, db, balance.ID); p.Status != "expired" {
+		t.Fatalf("balance payment is %s, want expired", p.Status)
+	}
+	if s, err := fake.GetCheckoutSession(balance.StripeRefID); err != nil || s.Status != payments.SessionExpired {
+		t.Fatalf("balance session %+v (%v), want it expired", s, err)
+	}
+}
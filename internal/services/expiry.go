@@ -0,0 +1,92 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExpiredPendingBookings returns pending bookings whose payment hold has
+// passed. Bookings created before holds were recorded expire BookingHold
+// after creation.
+func ExpiredPendingBookings(db *gorm.DB, now time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := db.Preload("Payments").
+		Where("status = ?", "pending").
+		Where("(expires_at IS NOT NULL AND expires_at < ?) OR (expires_at IS NULL AND created_at < ?)",
+			now.UTC(), now.Add(-config.BookingHold).UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// ExpireBooking cancels an unpaid pending booking: open checkout
+// sessions are expired, their payments marked expired and the slot freed.
+// Bookings with a succeeded payment, or whose session was completed in the
+// meantime, are left alone for the webhook to confirm; expired reports
+// whether the booking was actually cancelled. A split booking whose shares
+// were not all paid in time is cancelled too, refunding the paid shares.
+func ExpireBooking(db *gorm.DB, booking *models.Booking) (expired bool, err error) {
+	split, err := IsSplit(db, booking.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range booking.Payments {
+		if p.Status == "succeeded" && !split {
+			return false, nil
+		}
+	}
+
+	for _, p := range booking.Payments {
+		if p.Status != "pending" {
+			continue
+		}
+		completed, err := ExpireCheckoutSession(&p)
+		if err != nil {
+			return false, err
+		}
+		if completed {
+			log.Printf("⚠️ Booking %s: checkout session %s completed, skipping expiry", booking.ID, p.StripeRefID)
+			return false, nil
+		}
+	}
+
+	if split {
+		var unpaid int64
+		if err := db.Model(&models.BookingShare{}).
+			Where("booking_id = ? AND status = ?", booking.ID, "invited").
+			Count(&unpaid).Error; err != nil {
+			return false, err
+		}
+		if unpaid == 0 {
+			// All paid, the booking is being confirmed
+			return false, nil
+		}
+		if err := refundShares(db, booking); err != nil {
+			return false, err
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Payment{}).
+			Where("booking_id = ? AND status = ?", booking.ID, "pending").
+			Update("status", "expired").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.BookingShare{}).
+			Where("booking_id = ? AND status IN ?", booking.ID, activeShareStatuses).
+			Update("status", "expired").Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", booking.ID, "pending").
+			Update("status", "cancelled").Error
+	})
+	if err != nil {
+		return false, err
+	}
+	ReleaseSlot(db, booking.ID, booking.FieldID)
+	return true, nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestExpireBookingWithLostFakeSession(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	payment := openTestCheckout(t, db, fake, booking, false)
+
+	// The process that created the session restarted
+	testutil.FakeProvider(t)
+
+	expired, err := ExpireBooking(db, booking)
+	if err != nil {
+		t.Fatalf("ExpireBooking: %v", err)
+	}
+	if !expired {
+		t.Fatal("booking with an unpayable session was not expired")
+	}
+
+	var stored models.Payment
+	db.First(&stored, "id = ?", payment.ID)
+	if stored.Status != "expired" {
+		t.Errorf("payment is %s, want expired", stored.Status)
+	}
+	var storedBooking models.Booking
+	db.First(&storedBooking, "id = ?", booking.ID)
+	if storedBooking.Status != "cancelled" {
+		t.Errorf("booking is %s, want cancelled", storedBooking.Status)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+// createTestBooking stores a booking of the field for [start, end), priced
+// from the field's rules, with the given status.
+func createTestBooking(t *testing.T, db *gorm.DB, user *models.User, field *models.Field, start, end time.Time, status string) *models.Booking {
+	t.Helper()
+
+	quote, err := QuoteBooking(db, field, start, end)
+	if err != nil {
+		t.Fatalf("quote booking: %v", err)
+	}
+	booking := &models.Booking{UserID: user.ID, FieldID: field.ID, StartTime: start, EndTime: end, Status: status}
+	booking.ID = uuid.New()
+	ApplyQuote(booking, quote)
+	if err := db.Create(booking).Error; err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+	booking.Field = *field
+	return booking
+}
+
+// openTestCheckout opens a fake checkout session for the full price of the
+// booking and records its pending payment. With paid the session is
+// completed and the payment marked succeeded.
+func openTestCheckout(t *testing.T, db *gorm.DB, fake *payments.FakeProvider, booking *models.Booking, paid bool) *models.Payment {
+	t.Helper()
+
+	s, err := NewCheckoutSession(fake, booking.Currency, []CheckoutItem{{Name: "Booking", Amount: booking.TotalPrice}}, map[string]string{
+		"booking_id": booking.ID.String(),
+	})
+	if err != nil {
+		t.Fatalf("open checkout session: %v", err)
+	}
+	payment := &models.Payment{
+		BookingID:   booking.ID,
+		Amount:      booking.TotalPrice,
+		Currency:    booking.Currency,
+		Status:      "pending",
+		StripeRefID: s.ID,
+		Provider:    fake.Name(),
+	}
+	if paid {
+		if _, err := fake.CompleteSession(s.ID); err != nil {
+			t.Fatalf("complete checkout session: %v", err)
+		}
+		payment.Status = "succeeded"
+	}
+	if err := db.Create(payment).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	booking.Payments = append(booking.Payments, *payment)
+	return payment
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/receipts"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IssueInvoice returns the invoice of a paid payment, issuing it with the
+// next number of the current year if it has none yet. The counter is
+// incremented in the same transaction, so numbers have no gaps.
+func IssueInvoice(db *gorm.DB, payment *models.Payment) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("payment_id = ?", payment.ID).First(&invoice).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		issued := time.Now()
+		year := issued.In(config.Location).Year()
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.InvoiceCounter{Year: year}).Error; err != nil {
+			return err
+		}
+		var counter models.InvoiceCounter
+		if err := tx.Model(&counter).Clauses(clause.Returning{}).
+			Where("year = ?", year).
+			Update("last", gorm.Expr("last + 1")).Error; err != nil {
+			return err
+		}
+
+		invoice = models.Invoice{
+			PaymentID: payment.ID,
+			Number:    fmt.Sprintf("%s-%d-%06d", config.InvoicePrefix, year, counter.Last),
+			Year:      year,
+			Sequence:  counter.Last,
+			IssuedAt:  issued,
+			Currency:  payment.Currency,
+			Total:     payment.Amount,
+			TaxName:   config.TaxName,
+			TaxRate:   config.TaxRate,
+			// Prices include tax
+			TaxAmount: RoundAmount(payment.Amount*config.TaxRate/(100+config.TaxRate), payment.Currency),
+		}
+		return tx.Create(&invoice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// BuildReceipt assembles the receipt of a payment from its invoice. The
+// payment needs Booking.User and Booking.Field loaded.
+func BuildReceipt(payment *models.Payment, invoice *models.Invoice) *receipts.Receipt {
+	booking := payment.Booking
+	currency := invoice.Currency
+	decimals := 2
+	if IsZeroDecimal(currency) {
+		decimals = 0
+	}
+
+	start := booking.StartTime.In(config.Location)
+	r := &receipts.Receipt{
+		InvoiceNumber: invoice.Number,
+		IssuedAt:      invoice.IssuedAt.In(config.Location),
+		Seller: receipts.Party{
+			Name:    config.CompanyName,
+			Address: config.CompanyAddress,
+			TaxID:   config.CompanyTaxID,
+		},
+		Customer: receipts.Party{Name: booking.User.Name, Email: booking.User.Email},
+
+		PaymentID: payment.ID.String(),
+		Reference: payment.StripeRefID,
+		Provider:  payment.Provider,
+		Status:    payment.Status,
+
+		Field:    booking.Field.Name,
+		Location: booking.Field.Location,
+		Start:    start,
+		End:      booking.EndTime.In(config.Location),
+
+		Currency:  currency,
+		Decimals:  decimals,
+		Total:     invoice.Total,
+		TaxName:   invoice.TaxName,
+		TaxRate:   invoice.TaxRate,
+		TaxAmount: invoice.TaxAmount,
+		NetPaid:   RoundAmount(invoice.Total-payment.RefundedAmount, currency),
+	}
+
+	r.Lines = append(r.Lines, receipts.Line{
+		Description: fmt.Sprintf("Field booking – %s (%s)", booking.Field.Name, start.Format("02 Jan 2006 15:04")),
+		Amount:      RoundAmount(invoice.Total+payment.DiscountAmount, currency),
+	})
+	if payment.DiscountAmount > 0 {
+		r.Lines = append(r.Lines, receipts.Line{
+			Description: "Promo " + payment.PromoCode,
+			Amount:      -payment.DiscountAmount,
+		})
+	}
+	if payment.RefundedAmount > 0 {
+		r.Refunds = append(r.Refunds, receipts.Line{Description: "Refunded", Amount: -payment.RefundedAmount})
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestIssueInvoiceNumbersAreSequential(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	year := time.Now().In(config.Location).Year()
+
+	seen := map[string]bool{}
+	for i := 1; i <= 5; i++ {
+		s := start.Add(time.Duration(i) * time.Hour)
+		booking := createTestBooking(t, db, user, field, s, s.Add(time.Hour), "confirmed")
+		payment := openTestCheckout(t, db, fake, booking, true)
+
+		invoice, err := IssueInvoice(db, payment)
+		if err != nil {
+			t.Fatalf("issue invoice %d: %v", i, err)
+		}
+		want := fmt.Sprintf("%s-%d-%06d", config.InvoicePrefix, year, i)
+		if invoice.Number != want || invoice.Sequence != i || invoice.Year != year {
+			t.Fatalf("invoice %d is %s (sequence %d of %d), want %s", i, invoice.Number, invoice.Sequence, invoice.Year, want)
+		}
+		if seen[invoice.Number] {
+			t.Fatalf("invoice number %s issued twice", invoice.Number)
+		}
+		seen[invoice.Number] = true
+
+		// Asking again returns the same invoice without using a number
+		again, err := IssueInvoice(db, payment)
+		if err != nil || again.ID != invoice.ID || again.Number != invoice.Number {
+			t.Fatalf("reissue invoice %d: %+v (%v), want %s", i, again, err, invoice.Number)
+		}
+	}
+
+	var counter models.InvoiceCounter
+	if err := db.First(&counter, "year = ?", year).Error; err != nil {
+		t.Fatalf("load invoice counter: %v", err)
+	}
+	if counter.Last != 5 {
+		t.Fatalf("invoice counter at %d, want 5", counter.Last)
+	}
+}
+
+func TestWebhookReplayIssuesOneInvoicePerPayment(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	// One session paying for two bookings
+	first := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	second := createTestBooking(t, db, user, field, start.Add(2*time.Hour), start.Add(3*time.Hour), "pending")
+	payment := openTestCheckout(t, db, fake, first, false)
+	other := models.Payment{ID: uuid.New(), BookingID: second.ID, Amount: second.TotalPrice, Currency: second.Currency,
+		Status: "pending", StripeRefID: payment.StripeRefID, Provider: fake.Name()}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	for _, id := range []uuid.UUID{payment.ID, other.ID} {
+		var count int64
+		db.Model(&models.Invoice{}).Where("payment_id = ?", id).Count(&count)
+		if count != 1 {
+			t.Fatalf("%d invoices for payment %s, want 1", count, id)
+		}
+	}
+	var numbers []string
+	db.Model(&models.Invoice{}).Order("sequence").Pluck("number", &numbers)
+	if len(numbers) != 2 || numbers[0] == numbers[1] {
+		t.Fatalf("invoice numbers %v, want two distinct ones", numbers)
+	}
+}
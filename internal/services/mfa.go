@@ -0,0 +1,302 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/totp"
+	"gorm.io/gorm"
+)
+
+// MFAChallengeTTL is how long a user has to enter their code after the
+// password check.
+const MFAChallengeTTL = 5 * time.Minute
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+	// ErrMFARequired is returned when disabling two-factor authentication
+	// for a user whose role requires it.
+	ErrMFARequired = errors.New("two-factor authentication is required for this account")
+	// ErrMFAEnrollmentTokenRequired is returned when enabling two-factor
+	// authentication required by the user's role without the token emailed
+	// by SetupMFA.
+	ErrMFAEnrollmentTokenRequired = errors.New("enrollment_token from the email sent on setup is required")
+)
+
+// MFAEnabled reports whether a user signs in with a second factor.
+func MFAEnabled(user *models.User) bool {
+	return user.TOTPEnabledAt != nil
+}
+
+// SetupMFA gives a user a new TOTP secret to add to their authenticator app
+// and returns it with its otpauth:// URL. It takes effect once EnableMFA
+// confirms a code generated with it. Users whose role requires two-factor
+// authentication are signed in with their password only until they enroll,
+// so the enrollment is confirmed out of band: they are emailed a token that
+// EnableMFA requires as well.
+func SetupMFA(db *gorm.DB, user *models.User) (secret, otpauthURL string, err error) {
+	if MFAEnabled(user) {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := db.Model(&models.User{}).Where("id = ?", user.ID).
+		Updates(map[string]any{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		return "", "", err
+	}
+	user.TOTPSecret, user.TOTPLastStep = secret, 0
+
+	if config.MFARequired(user.Role) {
+		if err := sendMFAEnrollmentToken(db, user); err != nil {
+			return "", "", err
+		}
+	}
+	return secret, totp.URL(config.MFAIssuer, user.Email, secret), nil
+}
+
+// sendMFAEnrollmentToken emails a user the token confirming their
+// enrollment in two-factor authentication. A new token revokes the previous
+// one.
+func sendMFAEnrollmentToken(db *gorm.DB, user *models.User) error {
+	token, err := IssueUserToken(db, user.ID, TokenMFAEnrollment, config.MFAEnrollmentTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nTwo-factor authentication is being set up for your BookMyField account. "+
+		"To turn it on, enter this token together with a code from your authenticator app:\n\n%s\n\n"+
+		"The token can be used once and expires in %s. If it was not you, reset your password right away.\n",
+		user.Name, token, config.MFAEnrollmentTTL)
+	return mailer.Send(user.Email, "Confirm two-factor authentication for BookMyField", body)
+}
+
+// IssueMFAEnrollmentToken issues a new enrollment token for the user with
+// the given email, for operators of accounts whose email cannot receive the
+// one sent by SetupMFA, like the seeded admin. The user must have called
+// SetupMFA; the previous token is revoked.
+func IssueMFAEnrollmentToken(db *gorm.DB, email string) (string, error) {
+	var user models.User
+	if err := db.First(&user, "email = ?", strings.ToLower(strings.TrimSpace(email))).Error; err != nil {
+		return "", err
+	}
+	if MFAEnabled(&user) {
+		return "", ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return "", ErrMFANotSetUp
+	}
+	return IssueUserToken(db, user.ID, TokenMFAEnrollment, config.MFAEnrollmentTTL)
+}
+
+// EnableMFA turns on two-factor authentication after checking a code of
+// the secret from SetupMFA and, when the user's role requires it, the
+// enrollment token emailed by SetupMFA, and returns the user's recovery
+// codes. Sessions from logins without the second factor are revoked.
+func EnableMFA(db *gorm.DB, user *models.User, code, enrollmentToken string) ([]string, error) {
+	if MFAEnabled(user) {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotSetUp
+	}
+	required := config.MFARequired(user.Role)
+	if required && enrollmentToken == "" {
+		return nil, ErrMFAEnrollmentTokenRequired
+	}
+	if err := checkTOTP(db, user, code); err != nil {
+		return nil, err
+	}
+	if required {
+		userID, err := ConsumeUserToken(db, TokenMFAEnrollment, enrollmentToken)
+		if err != nil {
+			return nil, err
+		}
+		if userID != user.ID {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	var codes []string
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("totp_enabled_at", now).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPEnabledAt = &now
+
+	if err := RevokeUserSessions(user.ID.String(), ""); err != nil {
+		log.Printf("⚠️ Failed to revoke sessions of user %s: %v", user.ID, err)
+	}
+	return codes, nil
+}
+
+// DisableMFA turns off two-factor authentication after checking a TOTP or
+// recovery code. Users whose role requires it cannot turn it off.
+func DisableMFA(db *gorm.DB, user *models.User, code string) error {
+	if !MFAEnabled(user) {
+		return ErrMFANotEnabled
+	}
+	if config.MFARequired(user.Role) {
+		return ErrMFARequired
+	}
+	if err := VerifyMFACode(db, user, code); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]any{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking a
+// TOTP or recovery code.
+func RegenerateRecoveryCodes(db *gorm.DB, user *models.User, code string) ([]string, error) {
+	if !MFAEnabled(user) {
+		return nil, ErrMFANotEnabled
+	}
+	if err := VerifyMFACode(db, user, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user)
+		return err
+	})
+	return codes, err
+}
+
+// RecoveryCodesLeft counts the unused recovery codes of a user.
+func RecoveryCodesLeft(db *gorm.DB, user *models.User) (int64, error) {
+	var n int64
+	err := db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&n).Error
+	return n, err
+}
+
+// StartMFAChallenge issues the single-use token a user who passed the first
+// factor exchanges, together with a code, for their tokens.
+func StartMFAChallenge(db *gorm.DB, user *models.User) (string, error) {
+	return IssueUserToken(db, user.ID, TokenMFAChallenge, MFAChallengeTTL)
+}
+
+// CompleteMFAChallenge checks a TOTP or recovery code for an MFA challenge
+// token and returns the user signing in. The token is used up either way,
+// so a wrong code means starting the login again.
+func CompleteMFAChallenge(db *gorm.DB, token, code string) (*models.User, error) {
+	userID, err := ConsumeUserToken(db, TokenMFAChallenge, token)
+	if err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !MFAEnabled(&user) {
+		return nil, ErrInvalidToken
+	}
+	if err := VerifyMFACode(db, &user, code); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// VerifyMFACode accepts a current TOTP code or an unused recovery code of a
+// user, which is then used up.
+func VerifyMFACode(db *gorm.DB, user *models.User, code string) error {
+	if err := checkTOTP(db, user, code); !errors.Is(err, ErrInvalidMFACode) {
+		return err
+	}
+	return useRecoveryCode(db, user, code)
+}
+
+// checkTOTP accepts a TOTP code of the user's secret that is newer than the
+// last one accepted.
+func checkTOTP(db *gorm.DB, user *models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok || user.TOTPSecret == "" {
+		return ErrInvalidMFACode
+	}
+	res := db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func useRecoveryCode(db *gorm.DB, user *models.User, code string) error {
+	hash := hashUserToken(normalizeRecoveryCode(code))
+	res := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	log.Printf("ℹ️ User %s signed in with a recovery code", user.ID)
+	return nil
+}
+
+// recoveryCodeAlphabet leaves out i, l, o and 1, which are easily confused
+// with each other. It has 32 characters so random bytes map to it evenly.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+// replaceRecoveryCodes deletes the recovery codes of a user and returns new
+// ones, formatted as xxxxx-xxxxx.
+func replaceRecoveryCodes(tx *gorm.DB, user *models.User) ([]string, error) {
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		for j, b := range raw {
+			raw[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		codes[i] = string(raw[:5]) + "-" + string(raw[5:])
+		rows[i] = models.RecoveryCode{UserID: user.ID, CodeHash: hashUserToken(normalizeRecoveryCode(codes[i]))}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
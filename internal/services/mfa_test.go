@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"github.com/qullDev/BookMyField/internal/totp"
+	"gorm.io/gorm"
+)
+
+// sentMail records the emails sent during a test.
+type sentMail struct{ to, subject, body []string }
+
+func (m *sentMail) Send(to, subject, body string) error {
+	m.to, m.subject, m.body = append(m.to, to), append(m.subject, subject), append(m.body, body)
+	return nil
+}
+
+func recordMail(t *testing.T) *sentMail {
+	t.Helper()
+
+	m := &sentMail{}
+	prev := mailer.Default
+	mailer.Default = m
+	t.Cleanup(func() { mailer.Default = prev })
+	return m
+}
+
+func requireMFAFor(t *testing.T, role string) {
+	t.Helper()
+
+	prev := config.MFARequiredRoles
+	config.MFARequiredRoles = map[string]bool{role: true}
+	t.Cleanup(func() { config.MFARequiredRoles = prev })
+}
+
+// currentCode is a TOTP code of the user's secret for now.
+func currentCode(t *testing.T, user *models.User) string {
+	t.Helper()
+
+	code, err := totp.Code(user.TOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	return code
+}
+
+func reloadUser(t *testing.T, db *gorm.DB, user *models.User) *models.User {
+	t.Helper()
+
+	var u models.User
+	if err := db.First(&u, "id = ?", user.ID).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	return &u
+}
+
+var enrollmentTokenPattern = regexp.MustCompile(`(?m)^([A-Za-z0-9_-]{43})$`)
+
+func TestRequiredMFAEnrollmentNeedsEmailedToken(t *testing.T) {
+	withoutRedis(t)
+	requireMFAFor(t, "admin")
+	sent := recordMail(t)
+	db := testutil.NewDB(t)
+	admin := testutil.CreateUser(t, db, "admin@example.com")
+	if err := db.Model(admin).Update("role", "admin").Error; err != nil {
+		t.Fatalf("make admin: %v", err)
+	}
+	admin.Role = "admin"
+
+	if _, _, err := SetupMFA(db, admin); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if len(sent.to) != 1 || sent.to[0] != admin.Email {
+		t.Fatalf("emails sent to %v, want one to %s", sent.to, admin.Email)
+	}
+	match := enrollmentTokenPattern.FindStringSubmatch(sent.body[0])
+	if match == nil {
+		t.Fatalf("no enrollment token in %q", sent.body[0])
+	}
+
+	// A password-only login cannot finish the enrollment by itself
+	if _, err := EnableMFA(db, admin, currentCode(t, admin), ""); !errors.Is(err, ErrMFAEnrollmentTokenRequired) {
+		t.Fatalf("enable without token: %v, want ErrMFAEnrollmentTokenRequired", err)
+	}
+	if _, err := EnableMFA(db, admin, currentCode(t, admin), "not-the-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("enable with a wrong token: %v, want ErrInvalidToken", err)
+	}
+	if MFAEnabled(reloadUser(t, db, admin)) {
+		t.Fatal("two-factor authentication enabled without the emailed token")
+	}
+
+	// The failed attempt used up the current code; accept it once more
+	admin = reloadUser(t, db, admin)
+	if err := db.Model(admin).Update("totp_last_step", 0).Error; err != nil {
+		t.Fatalf("reset last step: %v", err)
+	}
+	codes, err := EnableMFA(db, admin, currentCode(t, admin), match[1])
+	if err != nil {
+		t.Fatalf("enable with the emailed token: %v", err)
+	}
+	if len(codes) != recoveryCodeCount || !MFAEnabled(reloadUser(t, db, admin)) {
+		t.Fatalf("enabled with %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+}
+
+func TestOptionalMFAEnrollment(t *testing.T) {
+	withoutRedis(t)
+	requireMFAFor(t, "admin")
+	sent := recordMail(t)
+	db := testutil.NewDB(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+
+	if _, _, err := SetupMFA(db, user); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if len(sent.to) != 0 {
+		t.Fatalf("emails sent to %v, want none", sent.to)
+	}
+	if _, err := EnableMFA(db, user, currentCode(t, user), ""); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+}
+
+func TestIssueMFAEnrollmentTokenForUndeliverableEmail(t *testing.T) {
+	withoutRedis(t)
+	requireMFAFor(t, "admin")
+	recordMail(t)
+	db := testutil.NewDB(t)
+	admin := testutil.CreateUser(t, db, "admin@admin.com")
+	if err := db.Model(admin).Update("role", "admin").Error; err != nil {
+		t.Fatalf("make admin: %v", err)
+	}
+	admin.Role = "admin"
+
+	if _, err := IssueMFAEnrollmentToken(db, admin.Email); !errors.Is(err, ErrMFANotSetUp) {
+		t.Fatalf("token before setup: %v, want ErrMFANotSetUp", err)
+	}
+	if _, _, err := SetupMFA(db, admin); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	// The operator issues a token instead of the one lost in the mail
+	token, err := IssueMFAEnrollmentToken(db, " Admin@Admin.com ")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	if _, err := EnableMFA(db, admin, currentCode(t, admin), token); err != nil {
+		t.Fatalf("enable with the issued token: %v", err)
+	}
+	if _, err := IssueMFAEnrollmentToken(db, admin.Email); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Fatalf("token after enrolling: %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
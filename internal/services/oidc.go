@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/oidc"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OIDCLoginTTL is how long a user has to sign in at the provider.
+const OIDCLoginTTL = 10 * time.Minute
+
+var (
+	ErrInvalidOIDCState = errors.New("login state is invalid or expired")
+	// ErrOIDCStateMismatch is returned when the callback does not come from
+	// the browser that started the login, e.g. a link planted by someone
+	// else to sign the user in to their account.
+	ErrOIDCStateMismatch = errors.New("login was not started in this browser")
+	// ErrOIDCEmailNotVerified is returned when the provider does not vouch
+	// for the user's email address, which accounts are matched by.
+	ErrOIDCEmailNotVerified = errors.New("email address is not verified by the identity provider")
+)
+
+// oidcLogin is what is kept of a started login until the provider redirects
+// back with its state.
+type oidcLogin struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+func oidcLoginKey(stateHash string) string { return "oidc_login:" + stateHash }
+
+// StartOIDCLogin starts a login at provider and returns the URL the user
+// signs in at and the login's state, which the caller binds to the browser
+// for FinishOIDCLogin. State, nonce and PKCE verifier are kept for
+// OIDCLoginTTL in Redis when available, otherwise in the database.
+func StartOIDCLogin(ctx context.Context, db *gorm.DB, provider *oidc.Provider) (authURL, state string, err error) {
+	if state, err = oidc.RandomString(); err != nil {
+		return "", "", err
+	}
+	login := oidcLogin{Provider: provider.Name}
+	if login.Nonce, err = oidc.RandomString(); err != nil {
+		return "", "", err
+	}
+	if login.CodeVerifier, err = oidc.RandomString(); err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	hash := hashUserToken(state)
+	if rdb := config.RedisClient; rdb != nil {
+		data, _ := json.Marshal(login)
+		if err := rdb.Set(config.Ctx, oidcLoginKey(hash), data, OIDCLoginTTL).Err(); err == nil {
+			return authURL, state, nil
+		}
+	}
+
+	// Logins nobody came back from
+	db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLogin{})
+	err = db.Create(&models.OIDCLogin{
+		StateHash:    hash,
+		Provider:     login.Provider,
+		Nonce:        login.Nonce,
+		CodeVerifier: login.CodeVerifier,
+		ExpiresAt:    time.Now().Add(OIDCLoginTTL),
+	}).Error
+	return authURL, state, err
+}
+
+// consumeOIDCLogin uses up the login started with state at provider.
+func consumeOIDCLogin(db *gorm.DB, provider, state string) (*oidcLogin, error) {
+	hash := hashUserToken(state)
+
+	if rdb := config.RedisClient; rdb != nil {
+		data, err := rdb.GetDel(config.Ctx, oidcLoginKey(hash)).Bytes()
+		if err == nil {
+			var login oidcLogin
+			if err := json.Unmarshal(data, &login); err != nil || login.Provider != provider {
+				return nil, ErrInvalidOIDCState
+			}
+			return &login, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+	}
+
+	// Started while Redis was unavailable
+	var stored models.OIDCLogin
+	if err := db.Where("state_hash = ? AND expires_at > ?", hash, time.Now()).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	res := db.Delete(&models.OIDCLogin{}, "id = ?", stored.ID)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 || stored.Provider != provider {
+		return nil, ErrInvalidOIDCState
+	}
+	return &oidcLogin{Provider: stored.Provider, Nonce: stored.Nonce, CodeVerifier: stored.CodeVerifier}, nil
+}
+
+// FinishOIDCLogin completes a login at provider with the state and
+// authorization code it redirected back with, and returns the signed in
+// user. browserState is the state StartOIDCLogin bound to the browser the
+// callback came from; if it differs the login was started elsewhere and
+// ErrOIDCStateMismatch is returned. Users are found by their identity at the provider, then by verified
+// email address, which links the identity to the existing account; otherwise
+// a new account is created. An existing account whose email was never
+// verified may have been registered by someone else ahead of its owner, so
+// before linking it its password, two-factor authentication and sessions are
+// reset; the owner can set a password with the password reset flow.
+func FinishOIDCLogin(ctx context.Context, db *gorm.DB, provider *oidc.Provider, state, browserState, code string) (*models.User, error) {
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrOIDCStateMismatch
+	}
+	login, err := consumeOIDCLogin(db, provider.Name, state)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := provider.Authenticate(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return userForOIDCIdentity(db, provider.Name, claims)
+}
+
+func userForOIDCIdentity(db *gorm.DB, provider string, claims *oidc.Claims) (*models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, "id = ?", identity.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		email := strings.ToLower(strings.TrimSpace(claims.Email))
+		if email == "" || !claims.EmailVerified {
+			return ErrOIDCEmailNotVerified
+		}
+
+		now := time.Now()
+		err = tx.First(&user, "email = ?", email).Error
+		switch {
+		case err == nil:
+			// The provider vouches for the address
+			if user.EmailVerifiedAt == nil {
+				if err := resetUnverifiedUser(tx, &user, now); err != nil {
+					return err
+				}
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if user, err = newOIDCUser(email, claims.Name, now); err != nil {
+				return err
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// resetUnverifiedUser hands an account with an unverified email over to the
+// owner of the address: the password becomes a random one, two-factor
+// authentication is turned off and every session is revoked, so whoever
+// registered the account cannot use it anymore. The email is marked verified.
+func resetUnverifiedUser(tx *gorm.DB, user *models.User, verifiedAt time.Time) error {
+	hashed, err := randomPasswordHash()
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(user).Updates(map[string]any{
+		"password":          hashed,
+		"totp_secret":       "",
+		"totp_enabled_at":   nil,
+		"totp_last_step":    0,
+		"email_verified_at": verifiedAt,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	user.Password, user.TOTPSecret, user.TOTPEnabledAt, user.TOTPLastStep = hashed, "", nil, 0
+	user.EmailVerifiedAt = &verifiedAt
+	return RevokeUserSessions(user.ID.String(), "")
+}
+
+// randomPasswordHash hashes a random password nobody knows.
+func randomPasswordHash() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(base64.RawURLEncoding.EncodeToString(raw)), bcrypt.DefaultCost)
+	return string(hashed), err
+}
+
+// newOIDCUser is an account for someone signing up through a provider. Its
+// password is random; one can be set with the password reset flow.
+func newOIDCUser(email, name string, verifiedAt time.Time) (models.User, error) {
+	hashed, err := randomPasswordHash()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	return models.User{
+		ID:              uuid.New(),
+		Name:            name,
+		Email:           email,
+		Password:        hashed,
+		Role:            "user",
+		EmailVerifiedAt: &verifiedAt,
+	}, nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/oidc"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// testIssuer is an OpenID Connect provider serving discovery, its signing
+// keys and a token endpoint that answers every code with an ID token for
+// the current claims, after checking the PKCE verifier.
+type testIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// Set by the test before the callback
+	Subject       string
+	Email         string
+	EmailVerified bool
+
+	// Taken from the authorization URL
+	nonce     string
+	challenge string
+
+	// Returns the state bound to the browser the callback comes from,
+	// by default the one the login was started with
+	otherBrowser func(state string) string
+}
+
+func (iss *testIssuer) browserState(state string) string {
+	if iss.otherBrowser != nil {
+		return iss.otherBrowser(state)
+	}
+	return state
+}
+
+const testClientID = "bookmyfield-test"
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	iss := &testIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 iss.server.URL,
+			"authorization_endpoint": iss.server.URL + "/authorize",
+			"token_endpoint":         iss.server.URL + "/token",
+			"jwks_uri":               iss.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "test-key",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("client_id") != testClientID || oidc.Challenge(r.PostFormValue("code_verifier")) != iss.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": iss.idToken(), "token_type": "Bearer"})
+	})
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+func (iss *testIssuer) idToken() string {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            iss.server.URL,
+		"aud":            testClientID,
+		"sub":            iss.Subject,
+		"email":          iss.Email,
+		"email_verified": iss.EmailVerified,
+		"name":           "Pemain Baru",
+		"nonce":          iss.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(iss.key)
+	if err != nil {
+		iss.t.Fatalf("sign ID token: %v", err)
+	}
+	return signed
+}
+
+// login signs in through the issuer as if the user's browser followed the
+// authorization URL and came back to the callback.
+func (iss *testIssuer) login(db *gorm.DB) (*models.User, error) {
+	iss.t.Helper()
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "test",
+		Issuer:      iss.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost:8080/api/v1/auth/oidc/test/callback",
+	})
+	ctx := context.Background()
+	authURL, state, err := StartOIDCLogin(ctx, db, provider)
+	if err != nil {
+		iss.t.Fatalf("start login: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		iss.t.Fatalf("parse authorization URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("state") != state {
+		iss.t.Fatalf("authorization URL state %q, want %q", q.Get("state"), state)
+	}
+	iss.nonce, iss.challenge = q.Get("nonce"), q.Get("code_challenge")
+	return FinishOIDCLogin(ctx, db, provider, state, iss.browserState(state), "test-code")
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	iss := newTestIssuer(t)
+	iss.Subject, iss.Email, iss.EmailVerified = "sub-new", "New.Player@Example.com", true
+
+	user, err := iss.login(db)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.Email != "new.player@example.com" || user.EmailVerifiedAt == nil || user.Name != "Pemain Baru" {
+		t.Fatalf("user %+v, want a verified account for the provider's email", user)
+	}
+
+	// The identity signs in to the same account next time
+	again, err := iss.login(db)
+	if err != nil || again.ID != user.ID {
+		t.Fatalf("second login: user %v (%v), want %s", again, err, user.ID)
+	}
+}
+
+func TestOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	iss := newTestIssuer(t)
+	iss.Subject, iss.Email, iss.EmailVerified = "sub-unverified", "player@example.com", false
+
+	if _, err := iss.login(db); !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Fatalf("login with an unverified email: %v, want ErrOIDCEmailNotVerified", err)
+	}
+}
+
+func TestOIDCLoginTakesOverUnverifiedAccount(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+
+	// Someone registered the address without being able to verify it and
+	// turned on two-factor authentication
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("squatter-password"), bcrypt.MinCost)
+	now := time.Now()
+	squatted := models.User{
+		ID:            uuid.New(),
+		Name:          "Squatter",
+		Email:         "victim@example.com",
+		Password:      string(hashed),
+		Role:          "user",
+		TOTPSecret:    "JBSWY3DPEHPK3PXP",
+		TOTPEnabledAt: &now,
+	}
+	if err := db.Create(&squatted).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := db.Create(&models.RecoveryCode{UserID: squatted.ID, CodeHash: "hash"}).Error; err != nil {
+		t.Fatalf("create recovery code: %v", err)
+	}
+
+	iss := newTestIssuer(t)
+	iss.Subject, iss.Email, iss.EmailVerified = "sub-victim", "victim@example.com", true
+	user, err := iss.login(db)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.ID != squatted.ID {
+		t.Fatalf("signed in as %s, want the existing account %s", user.ID, squatted.ID)
+	}
+
+	var stored models.User
+	if err := db.First(&stored, "id = ?", squatted.ID).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("squatter-password")) == nil {
+		t.Fatal("the squatter's password still works")
+	}
+	if stored.TOTPSecret != "" || stored.TOTPEnabledAt != nil {
+		t.Fatal("the squatter's two-factor authentication is still enabled")
+	}
+	if stored.EmailVerifiedAt == nil {
+		t.Fatal("email not marked verified")
+	}
+	var codes int64
+	db.Model(&models.RecoveryCode{}).Where("user_id = ?", squatted.ID).Count(&codes)
+	if codes != 0 {
+		t.Fatalf("%d recovery codes left, want none", codes)
+	}
+	var identities int64
+	db.Model(&models.UserIdentity{}).Where("user_id = ? AND subject = ?", squatted.ID, "sub-victim").Count(&identities)
+	if identities != 1 {
+		t.Fatalf("%d identities linked, want 1", identities)
+	}
+}
+
+func TestOIDCLoginKeepsVerifiedAccount(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	existing := testutil.CreateUser(t, db, "player@example.com")
+
+	iss := newTestIssuer(t)
+	iss.Subject, iss.Email, iss.EmailVerified = "sub-player", "player@example.com", true
+	user, err := iss.login(db)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	var stored models.User
+	if err := db.First(&stored, "id = ?", user.ID).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if stored.ID != existing.ID || stored.Password != existing.Password {
+		t.Fatal("verified account was not linked as is")
+	}
+}
+
+func TestOIDCLoginRequiresStartingBrowser(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	iss := newTestIssuer(t)
+	iss.Subject, iss.Email, iss.EmailVerified = "sub-attacker", "attacker@example.com", true
+
+	for name, browserState := range map[string]func(string) string{
+		"no state cookie":     func(string) string { return "" },
+		"other login's state": func(string) string { return "state-of-another-login" },
+	} {
+		iss.otherBrowser = browserState
+		if _, err := iss.login(db); !errors.Is(err, ErrOIDCStateMismatch) {
+			t.Errorf("%s: %v, want ErrOIDCStateMismatch", name, err)
+		}
+	}
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 0 {
+		t.Fatalf("%d accounts created, want none", users)
+	}
+
+	// The login can still be completed in the browser that started it
+	iss.otherBrowser = nil
+	if _, err := iss.login(db); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+}
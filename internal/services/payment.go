@@ -0,0 +1,282 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+// fakeProvider is the name of the in-process payments.FakeProvider.
+const fakeProvider = "fake"
+
+const (
+	checkoutSuccessURL = "https://bookmyfield-production.up.railway.app/success?session_id={CHECKOUT_SESSION_ID}"
+	checkoutCancelURL  = "https://bookmyfield-production.up.railway.app/cancel"
+)
+
+// CheckoutItem is one line item of a checkout session, with the amount in
+// major currency units.
+type CheckoutItem struct {
+	Name   string
+	Amount float64
+}
+
+// NewCheckoutSession opens a checkout session with the provider charging the
+// items in the given currency.
+func NewCheckoutSession(provider payments.Provider, currency string, items []CheckoutItem, metadata map[string]string) (*payments.CheckoutSession, error) {
+	req := payments.CheckoutRequest{
+		Currency:   currency,
+		Metadata:   metadata,
+		SuccessURL: checkoutSuccessURL,
+		CancelURL:  checkoutCancelURL,
+	}
+	for _, item := range items {
+		req.Items = append(req.Items, payments.CheckoutItem{Name: item.Name, Amount: ToMinorUnits(item.Amount, currency)})
+	}
+	return provider.CreateCheckoutSession(req)
+}
+
+// reservationPrefix starts the placeholder reference of payments whose
+// checkout session is not opened yet.
+const reservationPrefix = "reserved_"
+
+// NewReservationRef returns a placeholder reference for payments recorded
+// before their checkout session is opened, see OpenReservedCheckout.
+func NewReservationRef() string {
+	return reservationPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// OpenReservedCheckout opens the checkout session charging items for the
+// pending payments recorded under the placeholder ref and moves them to the
+// session ID. Payments are recorded first, in the transaction that reserves
+// promo codes and wallet balance, so no lock is held while the provider is
+// called and no session exists without its payments. If opening or recording
+// the session fails the payments are marked failed, which releases what they
+// reserved, and an opened session is expired.
+func OpenReservedCheckout(db *gorm.DB, provider payments.Provider, ref, currency string, items []CheckoutItem, metadata map[string]string) (*payments.CheckoutSession, error) {
+	s, err := NewCheckoutSession(provider, currency, items, metadata)
+	if err == nil {
+		err = db.Model(&models.Payment{}).Where("stripe_ref_id = ?", ref).Update("stripe_ref_id", s.ID).Error
+		if err != nil {
+			if _, xerr := provider.ExpireCheckoutSession(s.ID); xerr != nil {
+				log.Printf("❌ Failed to expire unrecorded checkout session %s: %v", s.ID, xerr)
+			}
+		}
+	}
+	if err != nil {
+		if uerr := db.Model(&models.Payment{}).
+			Where("stripe_ref_id = ? AND status = ?", ref, "pending").
+			Update("status", "failed").Error; uerr != nil {
+			log.Printf("❌ Failed to release payments reserved under %s: %v", ref, uerr)
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// ExpireCheckoutSession expires the open checkout session of a pending
+// payment so it can no longer be paid. completed reports whether it was paid
+// in the meantime.
+func ExpireCheckoutSession(payment *models.Payment) (completed bool, err error) {
+	// A wallet part goes with the provider payment of the same session
+	if payment.Provider == WalletProvider {
+		return false, nil
+	}
+	// No session was opened for it, e.g. the process stopped in between
+	if strings.HasPrefix(payment.StripeRefID, reservationPrefix) {
+		return false, nil
+	}
+	provider, err := payments.Get(payment.Provider)
+	if err != nil {
+		// Fake sessions only live in the process that created them, with
+		// the fake provider disabled nobody can pay them anymore
+		if payment.Provider == fakeProvider {
+			return false, nil
+		}
+		return false, err
+	}
+	s, err := provider.ExpireCheckoutSession(payment.StripeRefID)
+	if err != nil {
+		// Likewise for sessions created before a restart
+		if provider.Name() == fakeProvider {
+			return false, nil
+		}
+		return false, fmt.Errorf("expire checkout session %s: %w", payment.StripeRefID, err)
+	}
+	return s.Status == payments.SessionComplete, nil
+}
+
+// HandlePaymentEvent applies a verified webhook event to the payments of its
+// checkout session or payment intent. Unknown event types are ignored.
+func HandlePaymentEvent(db *gorm.DB, event *payments.Event) error {
+	switch event.Type {
+	case payments.EventChargeRefunded:
+		return ApplyProviderRefund(db, event)
+	case payments.EventDisputeCreated:
+		return OpenDispute(db, event.PaymentIntentID)
+	case payments.EventDisputeClosed:
+		return CloseDispute(db, event.PaymentIntentID, event.DisputeStatus)
+	case payments.EventPaymentIntentFailed:
+		return FailPayments(db, "payment_intent_id", event.PaymentIntentID, event.FailureMessage)
+	}
+
+	if event.Session == nil {
+		return nil
+	}
+
+	switch event.Type {
+	case payments.EventCheckoutCompleted, payments.EventAsyncPaymentSucceeded:
+		if event.Session.PaymentIntentID != "" {
+			if err := db.Model(&models.Payment{}).
+				Where("stripe_ref_id = ?", event.Session.ID).
+				Update("payment_intent_id", event.Session.PaymentIntentID).Error; err != nil {
+				return err
+			}
+		}
+		// Delayed payment methods complete the session unpaid and settle
+		// with async_payment_succeeded or async_payment_failed later
+		if !event.Session.Paid {
+			return nil
+		}
+		return MarkCheckoutSucceeded(db, event.Session.ID)
+	case payments.EventCheckoutExpired:
+		if err := expireWalletTopUps(db, event.Session.ID, "expired"); err != nil {
+			return err
+		}
+		return db.Model(&models.Payment{}).
+			Where("stripe_ref_id = ? AND status = ?", event.Session.ID, "pending").
+			Update("status", "expired").Error
+	case payments.EventAsyncPaymentFailed:
+		if err := expireWalletTopUps(db, event.Session.ID, "failed"); err != nil {
+			return err
+		}
+		return FailPayments(db, "stripe_ref_id", event.Session.ID, "")
+	}
+	return nil
+}
+
+// MarkCheckoutSucceeded marks every payment created for a checkout session as
+// succeeded and confirms the bookings they pay for. A session may pay for a
+// single booking, for all occurrences of a booking series or for shares of a
+// split booking, which is only confirmed once all its shares are paid.
+// Bookings of which only the deposit is paid become partially_paid.
+// Shares paid after their booking's hold ran out are refunded, as is money
+// for bookings no longer awaiting payment. Completing a session again, e.g.
+// on a replayed webhook, changes nothing.
+func MarkCheckoutSucceeded(db *gorm.DB, sessionID string) error {
+	var settled []uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := debitWalletPayments(tx, sessionID); err != nil {
+			return err
+		}
+		if err := creditWalletTopUps(tx, sessionID); err != nil {
+			return err
+		}
+		// Payments refunded or disputed since are left alone
+		unsettled := tx.Model(&models.Payment{}).
+			Where("stripe_ref_id = ? AND status IN ?", sessionID, unsettledPaymentStatuses)
+		if err := unsettled.Session(&gorm.Session{}).Pluck("id", &settled).Error; err != nil {
+			return err
+		}
+		if len(settled) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Payment{}).
+			Where("id IN ?", settled).
+			Update("status", "succeeded").Error; err != nil {
+			return err
+		}
+		if err := markSharesPaid(tx, sessionID); err != nil {
+			return err
+		}
+		paid := tx.Model(&models.Payment{}).Select("booking_id").Where("stripe_ref_id = ?", sessionID)
+		unpaidShares := tx.Model(&models.BookingShare{}).Select("booking_id").Where("status IN ?", []string{"invited", "expired"})
+		deposits := tx.Model(&models.Payment{}).Select("booking_id").
+			Where("stripe_ref_id = ? AND purpose = ?", sessionID, PurposeDeposit)
+		if err := tx.Model(&models.Booking{}).
+			Where("id IN (?) AND id NOT IN (?) AND id NOT IN (?)", paid, unpaidShares, deposits).
+			Where("status IN ?", awaitingPaymentStatuses).
+			Update("status", "confirmed").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Booking{}).
+			Where("id IN (?) AND status = ?", deposits, "pending").
+			Update("status", "partially_paid").Error; err != nil {
+			return err
+		}
+		// Bookings offered from the waitlist have been taken
+		if err := tx.Model(&models.WaitlistEntry{}).
+			Where("booking_id IN (?) AND booking_id NOT IN (?) AND status = ?", paid, unpaidShares, "offered").
+			Update("status", "booked").Error; err != nil {
+			return err
+		}
+
+		var payments []models.Payment
+		if err := tx.Where("stripe_ref_id = ?", sessionID).Find(&payments).Error; err != nil {
+			return err
+		}
+		for i := range payments {
+			if _, err := IssueInvoice(tx, &payments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := refundExpiredShares(db, sessionID); err != nil {
+		return err
+	}
+	return refundLatePayments(db, settled)
+}
+
+// unsettledPaymentStatuses are the statuses of payments a completed checkout
+// session marks succeeded; the session may be paid after it expired.
+var unsettledPaymentStatuses = []string{"pending", "expired", "failed"}
+
+// awaitingPaymentStatuses are the statuses of bookings a payment confirms.
+var awaitingPaymentStatuses = []string{"pending", "partially_paid"}
+
+// refundLatePayments refunds the payments just marked succeeded whose
+// booking was cancelled, or its hold ran out, before they arrived; the slot
+// may have been booked again.
+func refundLatePayments(db *gorm.DB, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	cancelled := db.Model(&models.Booking{}).Select("id").Where("status = ?", "cancelled")
+	var late []models.Payment
+	if err := db.Preload("Booking").
+		Where("id IN ? AND status = ? AND booking_id IN (?)", ids, "succeeded", cancelled).
+		Find(&late).Error; err != nil {
+		return err
+	}
+	for i := range late {
+		p := &late[i]
+		log.Printf("⚠️ Payment %s arrived for %s booking %s, refunding it", p.ID, p.Booking.Status, p.BookingID)
+		if _, err := RefundPayment(db, p, RoundAmount(p.Amount-p.RefundedAmount, p.Currency)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PaidAmount is what has been paid for a booking: succeeded payments minus
+// what was partially refunded from them. Fully refunded payments are
+// marked refunded and no longer count.
+func PaidAmount(payments []models.Payment) float64 {
+	var paid float64
+	for _, p := range payments {
+		if p.Status == "succeeded" {
+			paid += p.Amount - p.RefundedAmount
+		}
+	}
+	return paid
+}
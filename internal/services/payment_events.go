@@ -0,0 +1,201 @@
+package services
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+// FailPayments marks the pending payments whose column (stripe_ref_id or
+// payment_intent_id) equals value as failed. Their bookings are cancelled
+// right away if their hold has already passed; otherwise the customer can
+// still pay with a new checkout session before the expiry worker cancels
+// them.
+func FailPayments(db *gorm.DB, column, value, reason string) error {
+	if value == "" {
+		return nil
+	}
+	pending := func() *gorm.DB {
+		return db.Where(column+" = ? AND status = ?", value, "pending")
+	}
+
+	var failed []models.Payment
+	if err := pending().Find(&failed).Error; err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	bookingIDs := make([]uuid.UUID, 0, len(failed))
+	for _, p := range failed {
+		bookingIDs = append(bookingIDs, p.BookingID)
+	}
+	if err := pending().Model(&models.Payment{}).
+		Updates(map[string]interface{}{"status": "failed", "failure_reason": reason}).Error; err != nil {
+		return err
+	}
+
+	lapsed, err := ExpiredPendingBookings(db.Where("id IN ?", bookingIDs), time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range lapsed {
+		if _, err := ExpireBooking(db, &lapsed[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyProviderRefund records refunds made outside the app, e.g. in the
+// Stripe dashboard. event.AmountRefunded is the total refunded for the
+// payment intent; what exceeds the refunds already recorded on its payments
+// is spread over them in order. A booking whose payments are fully refunded
+// this way is cancelled.
+func ApplyProviderRefund(db *gorm.DB, event *payments.Event) error {
+	if event.PaymentIntentID == "" {
+		return nil
+	}
+
+	var paid []models.Payment
+	if err := db.Where("payment_intent_id = ? AND status IN ?", event.PaymentIntentID, []string{"succeeded", "refunded"}).
+		Order("created_at").Find(&paid).Error; err != nil {
+		return err
+	}
+	if len(paid) == 0 {
+		log.Printf("⚠️ Refund for unknown payment intent %s ignored", event.PaymentIntentID)
+		return nil
+	}
+
+	_, err := recordRefundTotal(db, paid, FromMinorUnits(event.AmountRefunded, paid[0].Currency))
+	return err
+}
+
+// ProviderRefunded sums what was refunded from payments through their
+// provider, leaving out refunds credited to the wallet.
+func ProviderRefunded(paid []models.Payment) float64 {
+	var refunded float64
+	for _, p := range paid {
+		refunded += p.RefundedAmount - p.WalletRefundedAmount
+	}
+	return refunded
+}
+
+// recordRefundTotal brings the refunds recorded on the payments of one
+// checkout up to total, the amount refunded according to the provider, and
+// returns the amount that was missing.
+func recordRefundTotal(db *gorm.DB, paid []models.Payment, total float64) (float64, error) {
+	currency := paid[0].Currency
+	recorded := ProviderRefunded(paid)
+	// Refunds issued by the app are already recorded
+	extra := RoundAmount(total-recorded, currency)
+	if extra <= 0 {
+		return 0, nil
+	}
+	missing := extra
+
+	var refunded []models.Payment
+	for i := range paid {
+		p := &paid[i]
+		if extra <= 0 {
+			break
+		}
+		// Wallet parts were not charged by the provider
+		if p.Status != "succeeded" || p.Provider == WalletProvider {
+			continue
+		}
+		amount := math.Min(p.Amount-p.RefundedAmount, extra)
+		if amount <= 0 {
+			continue
+		}
+		extra = RoundAmount(extra-amount, currency)
+		p.RefundedAmount = RoundAmount(p.RefundedAmount+amount, currency)
+		if p.RefundedAmount >= p.Amount {
+			p.Status = "refunded"
+			refunded = append(refunded, *p)
+		}
+		if err := db.Model(p).Updates(map[string]interface{}{
+			"refunded_amount": p.RefundedAmount,
+			"status":          p.Status,
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	for _, p := range refunded {
+		if err := cancelUnpaidBooking(db, p.BookingID); err != nil {
+			return 0, err
+		}
+	}
+	return missing, nil
+}
+
+// OpenDispute marks the succeeded payments of a payment intent as disputed.
+// Their bookings stay confirmed until the dispute is decided.
+func OpenDispute(db *gorm.DB, paymentIntentID string) error {
+	if paymentIntentID == "" {
+		return nil
+	}
+	return db.Model(&models.Payment{}).
+		Where("payment_intent_id = ? AND status = ?", paymentIntentID, "succeeded").
+		Update("status", "disputed").Error
+}
+
+// CloseDispute settles the disputed payments of a payment intent: a won
+// dispute makes them succeeded again, a lost one (chargeback) marks them
+// charged_back and cancels the bookings that are no longer paid.
+func CloseDispute(db *gorm.DB, paymentIntentID, outcome string) error {
+	if paymentIntentID == "" {
+		return nil
+	}
+
+	status := "succeeded"
+	if outcome == payments.DisputeLost {
+		status = "charged_back"
+	}
+
+	var disputed []models.Payment
+	if err := db.Where("payment_intent_id = ? AND status = ?", paymentIntentID, "disputed").Find(&disputed).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.Payment{}).
+		Where("payment_intent_id = ? AND status = ?", paymentIntentID, "disputed").
+		Update("status", status).Error; err != nil {
+		return err
+	}
+
+	if status == "charged_back" {
+		for _, p := range disputed {
+			if err := cancelUnpaidBooking(db, p.BookingID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cancelUnpaidBooking cancels a confirmed or partially paid booking that has
+// nothing paid left and frees its slot if it is still ahead.
+func cancelUnpaidBooking(db *gorm.DB, bookingID uuid.UUID) error {
+	var booking models.Booking
+	if err := db.Preload("Payments").First(&booking, "id = ?", bookingID).Error; err != nil {
+		return err
+	}
+	if (booking.Status != "confirmed" && booking.Status != "partially_paid") || PaidAmount(booking.Payments) > 0 {
+		return nil
+	}
+
+	if err := db.Model(&booking).Update("status", "cancelled").Error; err != nil {
+		return err
+	}
+	if booking.EndTime.After(time.Now()) {
+		ReleaseSlot(db, booking.ID, booking.FieldID)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func loadPayment(t *testing.T, db *gorm.DB, id interface{}) *models.Payment {
+	t.Helper()
+
+	var p models.Payment
+	if err := db.First(&p, "id = ?", id).Error; err != nil {
+		t.Fatalf("load payment: %v", err)
+	}
+	return &p
+}
+
+func loadBooking(t *testing.T, db *gorm.DB, id interface{}) *models.Booking {
+	t.Helper()
+
+	var b models.Booking
+	if err := db.First(&b, "id = ?", id).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	return &b
+}
+
+func TestMarkCheckoutSucceededReplayKeepsRefund(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	payment := openTestCheckout(t, db, fake, booking, false)
+
+	if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+		t.Fatalf("mark succeeded: %v", err)
+	}
+	var paid models.Booking
+	if err := db.Preload("Payments").First(&paid, "id = ?", booking.ID).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	if _, err := CancelBooking(db, &paid, false); err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+
+	// The completion webhook is delivered again
+	if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+		t.Fatalf("mark succeeded again: %v", err)
+	}
+	if p := loadPayment(t, db, payment.ID); p.Status != "refunded" {
+		t.Fatalf("payment is %s after the replay, want refunded", p.Status)
+	}
+	if b := loadBooking(t, db, booking.ID); b.Status != "cancelled" {
+		t.Fatalf("booking is %s after the replay, want cancelled", b.Status)
+	}
+	if refunds := fake.Refunds(payment.StripeRefID); len(refunds) != 1 {
+		t.Fatalf("%d provider refunds, want 1", len(refunds))
+	}
+}
+
+func TestMarkCheckoutSucceededRefundsCancelledBooking(t *testing.T) {
+	for _, deposit := range []bool{false, true} {
+		db := testutil.NewDB(t)
+		fake := testutil.FakeProvider(t)
+		user := testutil.CreateUser(t, db, "player@example.com")
+		field := testutil.CreateField(t, db, 100000)
+		start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+		booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+		payment := openTestCheckout(t, db, fake, booking, false)
+		if deposit {
+			if err := db.Model(payment).Update("purpose", PurposeDeposit).Error; err != nil {
+				t.Fatalf("make deposit: %v", err)
+			}
+		}
+
+		// The hold runs out before the customer pays
+		if err := db.Model(booking).Update("status", "cancelled").Error; err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+			t.Fatalf("complete session: %v", err)
+		}
+		if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+			t.Fatalf("mark succeeded: %v", err)
+		}
+
+		if b := loadBooking(t, db, booking.ID); b.Status != "cancelled" {
+			t.Fatalf("deposit %v: booking is %s, want it still cancelled", deposit, b.Status)
+		}
+		p := loadPayment(t, db, payment.ID)
+		if p.Status != "refunded" || p.RefundedAmount != p.Amount {
+			t.Fatalf("deposit %v: payment is %s with %v of %v refunded, want a full refund", deposit, p.Status, p.RefundedAmount, p.Amount)
+		}
+		if refunds := fake.Refunds(payment.StripeRefID); len(refunds) != 1 {
+			t.Fatalf("deposit %v: %d provider refunds, want 1", deposit, len(refunds))
+		}
+	}
+}
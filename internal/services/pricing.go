@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPeriod = errors.New("end time must be after start time")
+
+// PriceQuote is the computed price of booking a field for a period.
+type PriceQuote struct {
+	Currency string      `json:"currency" example:"idr"`
+	Items    []PriceLine `json:"items"`
+	Total    float64     `json:"total" example:"400000"`
+}
+
+// PriceLine is one line of a PriceQuote.
+type PriceLine struct {
+	Description string    `json:"description" example:"Lapangan Futsal A - Standard rate"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Hours       float64   `json:"hours" example:"2"`
+	UnitPrice   float64   `json:"unit_price" example:"200000"` // price per hour
+	Amount      float64   `json:"amount" example:"400000"`
+}
+
+// QuoteBooking computes the price of booking the field between start and end.
+// The period is split into hour segments (in config.Location), further split
+// where a pricing rule's time window starts or ends, and each segment is
+// charged at the hourly price of the highest-priority matching pricing rule,
+// or Field.Price when no rule matches. Partial hours are charged pro rata.
+// Consecutive segments at the same rate are merged into one line and every
+// line is rounded to the precision of config.Currency.
+func QuoteBooking(db *gorm.DB, field *models.Field, start, end time.Time) (*PriceQuote, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidPeriod
+	}
+
+	var rules []models.PricingRule
+	if err := db.Where("field_id = ?", field.ID).Order("priority DESC, created_at").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	currency := config.Currency
+	var items []PriceLine
+	for segStart := start.In(config.Location); segStart.Before(end); {
+		segEnd := nextPriceBoundary(rules, segStart)
+		if segEnd.After(end) {
+			segEnd = end.In(config.Location)
+		}
+
+		price, rate := field.Price, "Standard rate"
+		if rule := matchPricingRule(rules, segStart); rule != nil {
+			price, rate = rule.PricePerHour, rule.Name
+		}
+		description := fmt.Sprintf("%s - %s", field.Name, rate)
+
+		if n := len(items); n > 0 && items[n-1].Description == description && items[n-1].UnitPrice == price {
+			items[n-1].EndTime = segEnd
+		} else {
+			items = append(items, PriceLine{
+				Description: description,
+				StartTime:   segStart,
+				EndTime:     segEnd,
+				UnitPrice:   price,
+			})
+		}
+		segStart = segEnd
+	}
+
+	quote := &PriceQuote{Currency: currency}
+	for i := range items {
+		items[i].Hours = items[i].EndTime.Sub(items[i].StartTime).Hours()
+		items[i].Amount = RoundAmount(items[i].UnitPrice*items[i].Hours, currency)
+		quote.Total += items[i].Amount
+	}
+	quote.Items = items
+	quote.Total = RoundAmount(quote.Total, currency)
+
+	return quote, nil
+}
+
+// ValidatePricingRule checks the price, weekdays, time window and date range of a rule.
+func ValidatePricingRule(rule *models.PricingRule) error {
+	if rule.PricePerHour < 0 {
+		return errors.New("price_per_hour must not be negative")
+	}
+	for _, d := range rule.Weekdays {
+		if d < 0 || d > 6 {
+			return fmt.Errorf("invalid weekday %d, expected 0 (Sunday) to 6 (Saturday)", d)
+		}
+	}
+	for _, t := range []string{rule.StartTime, rule.EndTime} {
+		if t == "" {
+			continue
+		}
+		if _, err := ParseClock(t); err != nil {
+			return err
+		}
+	}
+	if rule.StartTime != "" && rule.StartTime == rule.EndTime {
+		return errors.New("start_time and end_time must differ")
+	}
+	for _, d := range []string{rule.StartDate, rule.EndDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", d)
+		}
+	}
+	if rule.StartDate != "" && rule.EndDate != "" && rule.StartDate > rule.EndDate {
+		return errors.New("start_date must not be after end_date")
+	}
+	return nil
+}
+
+// nextPriceBoundary returns the end of the segment starting at t: the next
+// full hour, or the next start or end of a rule's time window if that comes
+// first, so rules with windows such as 17:30-21:00 are priced exactly.
+func nextPriceBoundary(rules []models.PricingRule, t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, config.Location)
+	for i := range rules {
+		for _, clock := range []string{rules[i].StartTime, rules[i].EndTime} {
+			if clock == "" {
+				continue
+			}
+			minutes, err := ParseClock(clock)
+			if err != nil {
+				continue
+			}
+			b := time.Date(t.Year(), t.Month(), t.Day(), 0, minutes, 0, 0, config.Location)
+			if b.After(t) && b.Before(next) {
+				next = b
+			}
+		}
+	}
+	return next
+}
+
+// matchPricingRule returns the first rule (rules are ordered by priority)
+// that applies to the segment starting at t.
+func matchPricingRule(rules []models.PricingRule, t time.Time) *models.PricingRule {
+	for i := range rules {
+		if pricingRuleMatches(&rules[i], t) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func pricingRuleMatches(rule *models.PricingRule, t time.Time) bool {
+	if len(rule.Weekdays) > 0 {
+		found := false
+		for _, d := range rule.Weekdays {
+			if d == int(t.Weekday()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	date := t.Format("2006-01-02")
+	if rule.StartDate != "" && date < rule.StartDate {
+		return false
+	}
+	if rule.EndDate != "" && date > rule.EndDate {
+		return false
+	}
+
+	from, until := 0, 24*60
+	if rule.StartTime != "" {
+		from, _ = ParseClock(rule.StartTime)
+	}
+	if rule.EndTime != "" {
+		until, _ = ParseClock(rule.EndTime)
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if from < until {
+		return minute >= from && minute < until
+	}
+	// window wraps around midnight, e.g. 22:00-02:00
+	return minute >= from || minute < until
+}
+
+// ApplyQuote copies a quote onto a booking so it is persisted together with it.
+func ApplyQuote(booking *models.Booking, quote *PriceQuote) {
+	booking.TotalPrice = quote.Total
+	booking.Currency = quote.Currency
+	booking.PriceItems = make([]models.BookingPriceItem, 0, len(quote.Items))
+	for _, line := range quote.Items {
+		booking.PriceItems = append(booking.PriceItems, models.BookingPriceItem{
+			BookingID:   booking.ID,
+			Description: line.Description,
+			StartTime:   line.StartTime,
+			EndTime:     line.EndTime,
+			Hours:       line.Hours,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.Amount,
+		})
+	}
+}
+
+// EnsureBookingPrice prices a booking created before prices were stored on
+// bookings, persisting the breakdown. Bookings that already have a price are
+// left untouched.
+func EnsureBookingPrice(db *gorm.DB, booking *models.Booking) error {
+	if booking.TotalPrice > 0 {
+		if len(booking.PriceItems) == 0 {
+			return db.Where("booking_id = ?", booking.ID).Order("start_time").Find(&booking.PriceItems).Error
+		}
+		return nil
+	}
+
+	var field models.Field
+	if err := db.First(&field, "id = ?", booking.FieldID).Error; err != nil {
+		return err
+	}
+
+	quote, err := QuoteBooking(db, &field, booking.StartTime, booking.EndTime)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("booking_id = ?", booking.ID).Delete(&models.BookingPriceItem{}).Error; err != nil {
+			return err
+		}
+		ApplyQuote(booking, quote)
+		if err := tx.Create(&booking.PriceItems).Error; err != nil {
+			return err
+		}
+		return tx.Model(booking).Updates(map[string]interface{}{
+			"total_price": booking.TotalPrice,
+			"currency":    booking.Currency,
+		}).Error
+	})
+}
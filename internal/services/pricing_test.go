@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestQuoteBookingSplitsAtRuleBoundaries(t *testing.T) {
+	db := testutil.NewDB(t)
+	field := testutil.CreateField(t, db, 100000)
+
+	rule := models.PricingRule{
+		FieldID:      field.ID,
+		Name:         "Evening",
+		PricePerHour: 200000,
+		StartTime:    "17:30",
+		EndTime:      "21:00",
+	}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	start := time.Date(2030, 1, 7, 17, 0, 0, 0, time.UTC)
+	quote, err := QuoteBooking(db, field, start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("QuoteBooking: %v", err)
+	}
+
+	// 17:00-17:30 standard, 17:30-19:00 evening
+	want := 0.5*100000 + 1.5*200000
+	if quote.Total != want {
+		t.Fatalf("total = %v, want %v", quote.Total, want)
+	}
+	if len(quote.Items) != 2 {
+		t.Fatalf("got %d price lines, want 2", len(quote.Items))
+	}
+	if got := quote.Items[1].StartTime; !got.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("evening rate starts at %v, want 17:30", got)
+	}
+}
+
+func TestQuoteBookingWrappingWindow(t *testing.T) {
+	db := testutil.NewDB(t)
+	field := testutil.CreateField(t, db, 100000)
+
+	rule := models.PricingRule{
+		FieldID:      field.ID,
+		Name:         "Night",
+		PricePerHour: 50000,
+		StartTime:    "22:15",
+		EndTime:      "01:45",
+	}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	start := time.Date(2030, 1, 7, 22, 0, 0, 0, time.UTC)
+	quote, err := QuoteBooking(db, field, start, start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("QuoteBooking: %v", err)
+	}
+
+	// 22:00-22:15 and 01:45-02:00 standard, 22:15-01:45 night
+	want := 0.5*100000 + 3.5*50000
+	if quote.Total != want {
+		t.Fatalf("total = %v, want %v", quote.Total, want)
+	}
+}
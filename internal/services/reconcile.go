@@ -0,0 +1,187 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+// Discrepancy is a checkout session whose payments disagree with the
+// provider.
+type Discrepancy struct {
+	SessionID      string      `json:"session_id"`
+	Provider       string      `json:"provider"`
+	PaymentIDs     []uuid.UUID `json:"payment_ids"`
+	BookingIDs     []uuid.UUID `json:"booking_ids"`
+	LocalStatus    string      `json:"local_status"`
+	ProviderStatus string      `json:"provider_status"`
+	Issue          string      `json:"issue"`
+	Action         string      `json:"action"`
+	Fixed          bool        `json:"fixed"`
+}
+
+// ReconcileReport is the outcome of a reconciliation run.
+type ReconcileReport struct {
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	DryRun        bool          `json:"dry_run"`
+	Checked       int           `json:"checked"` // checkout sessions
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Unresolved counts discrepancies that were not fixed.
+func (r *ReconcileReport) Unresolved() int {
+	n := 0
+	for _, d := range r.Discrepancies {
+		if !d.Fixed {
+			n++
+		}
+	}
+	return n
+}
+
+// Reconcile compares pending and succeeded payments updated since the given
+// time with the state of their checkout sessions at the provider and fixes
+// what webhooks missed:
+//   - pending but paid: marked succeeded, bookings confirmed
+//   - pending but expired: marked expired
+//   - refunds made at the provider but not recorded: recorded
+//
+// Succeeded payments the provider does not report as paid, and lookups that
+// fail, are only reported. With dryRun nothing is changed.
+func Reconcile(db *gorm.DB, since time.Time, dryRun bool) (*ReconcileReport, error) {
+	report := &ReconcileReport{StartedAt: time.Now(), DryRun: dryRun, Discrepancies: []Discrepancy{}}
+
+	var candidates []models.Payment
+	// Wallet parts follow the provider payment of their session
+	if err := db.Where("status IN ? AND updated_at >= ?", []string{"pending", "succeeded"}, since.UTC()).
+		Where("provider <> ?", WalletProvider).
+		Order("created_at").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	// One checkout session can pay several bookings (series)
+	type sessionKey struct{ provider, id string }
+	var keys []sessionKey
+	groups := map[sessionKey][]models.Payment{}
+	for _, p := range candidates {
+		// Sessions still being opened have nothing to compare yet
+		if p.StripeRefID == "" || strings.HasPrefix(p.StripeRefID, reservationPrefix) {
+			continue
+		}
+		k := sessionKey{p.Provider, p.StripeRefID}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], p)
+	}
+
+	for _, k := range keys {
+		report.Checked++
+		if d := reconcileSession(db, groups[k], dryRun); d != nil {
+			report.Discrepancies = append(report.Discrepancies, *d)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// reconcileSession checks the payments of one checkout session, all pending
+// or all succeeded, and returns the discrepancy found, if any.
+func reconcileSession(db *gorm.DB, group []models.Payment, dryRun bool) *Discrepancy {
+	first := group[0]
+	d := &Discrepancy{
+		SessionID:   first.StripeRefID,
+		Provider:    first.Provider,
+		LocalStatus: first.Status,
+	}
+	for _, p := range group {
+		d.PaymentIDs = append(d.PaymentIDs, p.ID)
+		d.BookingIDs = append(d.BookingIDs, p.BookingID)
+	}
+
+	provider, err := payments.Get(first.Provider)
+	if err != nil {
+		d.Issue, d.Action = err.Error(), "manual review"
+		return d
+	}
+	s, err := provider.GetCheckoutSession(first.StripeRefID)
+	if err != nil {
+		d.Issue, d.Action = "provider lookup failed: "+err.Error(), "manual review"
+		return d
+	}
+	d.ProviderStatus = s.Status
+	if s.Status == payments.SessionComplete && !s.Paid {
+		d.ProviderStatus += " (unpaid)"
+	}
+
+	if s.PaymentIntentID != "" && first.PaymentIntentID == "" && !dryRun {
+		db.Model(&models.Payment{}).
+			Where("stripe_ref_id = ?", s.ID).
+			Update("payment_intent_id", s.PaymentIntentID)
+	}
+
+	switch first.Status {
+	case "pending":
+		switch {
+		case s.Status == payments.SessionComplete && s.Paid:
+			d.Issue, d.Action = "paid at provider but pending locally", "mark succeeded and confirm bookings"
+			if !dryRun {
+				d.Fixed = fixed(d, MarkCheckoutSucceeded(db, s.ID))
+			}
+		case s.Status == payments.SessionExpired:
+			d.Issue, d.Action = "expired at provider but pending locally", "mark expired"
+			if !dryRun {
+				d.Fixed = fixed(d, db.Model(&models.Payment{}).
+					Where("stripe_ref_id = ? AND status = ?", s.ID, "pending").
+					Update("status", "expired").Error)
+			}
+		default:
+			return nil
+		}
+	case "succeeded":
+		if s.Status != payments.SessionComplete || !s.Paid {
+			d.Issue, d.Action = "succeeded locally but not paid at provider", "manual review"
+			return d
+		}
+
+		var paid []models.Payment
+		if err := db.Where("stripe_ref_id = ? AND status IN ?", s.ID, []string{"succeeded", "refunded"}).
+			Order("created_at").Find(&paid).Error; err != nil {
+			d.Issue, d.Action = "failed to load payments: "+err.Error(), "manual review"
+			return d
+		}
+		recorded := ProviderRefunded(paid)
+		refunded := FromMinorUnits(s.AmountRefunded, first.Currency)
+		switch diff := RoundAmount(refunded-recorded, first.Currency); {
+		case diff > 0:
+			d.Issue = fmt.Sprintf("refund of %.2f %s at provider not recorded", diff, first.Currency)
+			d.Action = "record refund"
+			if !dryRun {
+				_, err := recordRefundTotal(db, paid, refunded)
+				d.Fixed = fixed(d, err)
+			}
+		case diff < 0:
+			d.Issue = fmt.Sprintf("recorded refunds exceed the provider's by %.2f %s", -diff, first.Currency)
+			d.Action = "manual review"
+		default:
+			return nil
+		}
+	}
+	return d
+}
+
+// fixed reports whether a fix succeeded, noting its error on d otherwise.
+func fixed(d *Discrepancy, err error) bool {
+	if err != nil {
+		d.Action += " (failed: " + err.Error() + ")"
+		return false
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/dto"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+// MaxSeriesOccurrences caps the number of bookings a single series may create.
+const MaxSeriesOccurrences = 52
+
+var (
+	ErrInvalidRecurrence = errors.New("recurrence needs a frequency of weekly or biweekly and an until date or count")
+	ErrNoFreeOccurrence  = errors.New("none of the occurrences could be booked")
+)
+
+// Occurrences expands a weekly or biweekly recurrence starting with
+// [start, end). It stops after count occurrences or at the last occurrence
+// starting on or before until, whichever comes first, and never yields more
+// than MaxSeriesOccurrences. Wall-clock times are kept in config.Location.
+func Occurrences(start, end time.Time, frequency string, count int, until *time.Time) ([]Interval, error) {
+	step := 0
+	switch frequency {
+	case "weekly":
+		step = 7
+	case "biweekly":
+		step = 14
+	}
+	if step == 0 || (count <= 0 && until == nil) {
+		return nil, ErrInvalidRecurrence
+	}
+	if count <= 0 || count > MaxSeriesOccurrences {
+		count = MaxSeriesOccurrences
+	}
+
+	localStart, localEnd := start.In(config.Location), end.In(config.Location)
+	var occurrences []Interval
+	for i := 0; i < count; i++ {
+		s := localStart.AddDate(0, 0, i*step)
+		if until != nil && s.After(*until) {
+			break
+		}
+		occurrences = append(occurrences, Interval{Start: s, End: localEnd.AddDate(0, 0, i*step)})
+	}
+	return occurrences, nil
+}
+
+// CreateBookingSeries stores the series and every candidate booking whose
+// slot is free, in one transaction holding the field's booking lock. Taken
+// slots are returned as conflicts; if no candidate can be booked nothing is
+// stored and ErrNoFreeOccurrence is returned.
+func CreateBookingSeries(db *gorm.DB, series *models.BookingSeries, candidates []models.Booking) ([]models.Booking, []dto.OccurrenceConflict, error) {
+	var created []models.Booking
+	var conflicts []dto.OccurrenceConflict
+
+	err := WithSlotLock(db, series.FieldID, func(tx *gorm.DB) error {
+		created, conflicts = nil, nil
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+
+		for _, b := range candidates {
+			conflict, err := HasConflict(tx, b.FieldID, b.StartTime, b.EndTime, b.ID)
+			if err != nil {
+				return err
+			}
+			if conflict {
+				conflicts = append(conflicts, dto.OccurrenceConflict{StartTime: b.StartTime, EndTime: b.EndTime, Reason: ErrSlotTaken.Error()})
+				continue
+			}
+
+			b.SeriesID = &series.ID
+			if err := tx.Create(&b).Error; err != nil {
+				return err
+			}
+			created = append(created, b)
+		}
+
+		if len(created) == 0 {
+			return ErrNoFreeOccurrence
+		}
+		return nil
+	})
+
+	return created, conflicts, err
+}
+
+// SeriesOccurrenceCancellation is the outcome of cancelling one occurrence
+// of a series: Result when it was cancelled, Err otherwise.
+type SeriesOccurrenceCancellation struct {
+	Booking models.Booking
+	Result  *CancelResult
+	Err     error
+}
+
+// CancelBookingSeries cancels every upcoming occurrence of a series with
+// CancelBooking, refunding paid ones, and marks the series cancelled unless
+// an occurrence could not be cancelled. Past occurrences are left alone.
+func CancelBookingSeries(db *gorm.DB, series *models.BookingSeries, toWallet bool) ([]SeriesOccurrenceCancellation, error) {
+	var bookings []models.Booking
+	if err := db.Preload("Payments").
+		Where("series_id = ? AND status != ? AND start_time > ?", series.ID, "cancelled", time.Now().UTC()).
+		Order("start_time").
+		Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]SeriesOccurrenceCancellation, len(bookings))
+	failed := false
+	for i := range bookings {
+		result, err := CancelBooking(db, &bookings[i], toWallet)
+		results[i] = SeriesOccurrenceCancellation{Booking: bookings[i], Result: result, Err: err}
+		failed = failed || err != nil
+	}
+	if failed {
+		return results, nil
+	}
+
+	series.Status = "cancelled"
+	return results, db.Model(series).Update("status", "cancelled").Error
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestOccurrencesInJakarta(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	saved := config.Location
+	config.Location = jakarta
+	t.Cleanup(func() { config.Location = saved })
+
+	// Sunday 23:00 WIB is still Sunday locally but 16:00 UTC; Monday 06:00
+	// WIB is Sunday 23:00 UTC
+	lateSunday := time.Date(2026, 3, 1, 23, 0, 0, 0, jakarta)
+	earlyMonday := time.Date(2026, 3, 2, 6, 0, 0, 0, jakarta)
+	until := time.Date(2026, 3, 29, 23, 0, 0, 0, jakarta)
+
+	for _, tc := range []struct {
+		name      string
+		start     time.Time
+		length    time.Duration
+		frequency string
+		count     int
+		until     *time.Time
+		want      int
+		weekday   time.Weekday
+		step      int
+	}{
+		{"weekly late evening", lateSunday, time.Hour, "weekly", 4, nil, 4, time.Sunday, 7},
+		{"weekly across midnight", lateSunday, 2 * time.Hour, "weekly", 3, nil, 3, time.Sunday, 7},
+		{"weekly early morning", earlyMonday.In(time.UTC), time.Hour, "weekly", 3, nil, 3, time.Monday, 7},
+		{"biweekly late evening", lateSunday, time.Hour, "biweekly", 3, nil, 3, time.Sunday, 14},
+		{"weekly until the last Sunday", lateSunday, time.Hour, "weekly", 0, &until, 5, time.Sunday, 7},
+		{"biweekly until the last Sunday", lateSunday, time.Hour, "biweekly", 10, &until, 3, time.Sunday, 14},
+		{"capped", earlyMonday, time.Hour, "weekly", 100, nil, MaxSeriesOccurrences, time.Monday, 7},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Occurrences(tc.start, tc.start.Add(tc.length), tc.frequency, tc.count, tc.until)
+			if err != nil {
+				t.Fatalf("occurrences: %v", err)
+			}
+			if len(got) != tc.want {
+				t.Fatalf("%d occurrences, want %d", len(got), tc.want)
+			}
+			local := tc.start.In(jakarta)
+			for i, occ := range got {
+				s := occ.Start.In(jakarta)
+				if s.Weekday() != tc.weekday || s.Hour() != local.Hour() {
+					t.Errorf("occurrence %d starts %s, want %s at %02d:00 WIB", i, s.Format(time.RFC1123), tc.weekday, local.Hour())
+				}
+				if want := local.AddDate(0, 0, i*tc.step); !occ.Start.Equal(want) {
+					t.Errorf("occurrence %d starts %s, want %s", i, occ.Start, want)
+				}
+				if occ.End.Sub(occ.Start) != tc.length {
+					t.Errorf("occurrence %d lasts %s, want %s", i, occ.End.Sub(occ.Start), tc.length)
+				}
+			}
+		})
+	}
+}
+
+func TestOccurrencesInvalid(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	until := start.AddDate(0, 1, 0)
+	for _, tc := range []struct {
+		frequency string
+		count     int
+		until     *time.Time
+	}{
+		{"daily", 3, nil},
+		{"", 3, nil},
+		{"weekly", 0, nil},
+		{"monthly", 0, &until},
+	} {
+		if _, err := Occurrences(start, start.Add(time.Hour), tc.frequency, tc.count, tc.until); !errors.Is(err, ErrInvalidRecurrence) {
+			t.Errorf("%q with count %d: error %v, want ErrInvalidRecurrence", tc.frequency, tc.count, err)
+		}
+	}
+}
+
+// createTestSeries books a weekly series of the field with the given number
+// of one-hour occurrences, the first at start.
+func createTestSeries(t *testing.T, db *gorm.DB, user *models.User, field *models.Field, start time.Time, occurrences int) (*models.BookingSeries, []models.Booking) {
+	t.Helper()
+
+	series := &models.BookingSeries{ID: uuid.New(), UserID: user.ID, FieldID: field.ID, Frequency: "weekly", Count: occurrences, Status: "active"}
+	got, err := Occurrences(start, start.Add(time.Hour), series.Frequency, occurrences, nil)
+	if err != nil {
+		t.Fatalf("occurrences: %v", err)
+	}
+	var candidates []models.Booking
+	for _, occ := range got {
+		quote, err := QuoteBooking(db, field, occ.Start, occ.End)
+		if err != nil {
+			t.Fatalf("quote booking: %v", err)
+		}
+		b := models.Booking{ID: uuid.New(), UserID: user.ID, FieldID: field.ID, StartTime: occ.Start, EndTime: occ.End, Status: "pending"}
+		ApplyQuote(&b, quote)
+		candidates = append(candidates, b)
+	}
+	created, conflicts, err := CreateBookingSeries(db, series, candidates)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("create series: %v, conflicts %+v", err, conflicts)
+	}
+	return series, created
+}
+
+func TestCreateBookingSeriesConflicts(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	for _, tc := range []struct {
+		name    string
+		taken   []int // occurrences already booked by someone else
+		created int
+		err     error
+	}{
+		{"free", nil, 3, nil},
+		{"one taken", []int{1}, 2, nil},
+		{"all taken", []int{0, 1, 2}, 0, ErrNoFreeOccurrence},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := testutil.NewDB(t)
+			user := testutil.CreateUser(t, db, "player@example.com")
+			other := testutil.CreateUser(t, db, "other@example.com")
+			field := testutil.CreateField(t, db, 100000)
+			for _, i := range tc.taken {
+				s := start.AddDate(0, 0, 7*i)
+				createTestBooking(t, db, other, field, s, s.Add(time.Hour), "confirmed")
+			}
+
+			series := &models.BookingSeries{ID: uuid.New(), UserID: user.ID, FieldID: field.ID, Frequency: "weekly", Count: 3, Status: "active"}
+			var candidates []models.Booking
+			for i := 0; i < 3; i++ {
+				s := start.AddDate(0, 0, 7*i)
+				candidates = append(candidates, models.Booking{ID: uuid.New(), UserID: user.ID, FieldID: field.ID,
+					StartTime: s, EndTime: s.Add(time.Hour), Status: "pending"})
+			}
+
+			created, conflicts, err := CreateBookingSeries(db, series, candidates)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("create series: %v, want %v", err, tc.err)
+			}
+			if len(created) != tc.created || len(conflicts) != len(tc.taken) {
+				t.Fatalf("%d created with %d conflicts, want %d with %d", len(created), len(conflicts), tc.created, len(tc.taken))
+			}
+			for j, i := range tc.taken {
+				if !conflicts[j].StartTime.Equal(candidates[i].StartTime) || conflicts[j].Reason != ErrSlotTaken.Error() {
+					t.Errorf("conflict %d is %+v, want occurrence %d taken", j, conflicts[j], i)
+				}
+			}
+			for _, b := range created {
+				if b.SeriesID == nil || *b.SeriesID != series.ID {
+					t.Errorf("booking %s is not part of the series", b.ID)
+				}
+			}
+
+			var stored int64
+			db.Model(&models.Booking{}).Where("user_id = ?", user.ID).Count(&stored)
+			if stored != int64(tc.created) {
+				t.Fatalf("%d bookings stored, want %d", stored, tc.created)
+			}
+			var storedSeries int64
+			db.Model(&models.BookingSeries{}).Count(&storedSeries)
+			if want := int64(min(tc.created, 1)); storedSeries != want {
+				t.Fatalf("%d series stored, want %d", storedSeries, want)
+			}
+		})
+	}
+}
+
+func TestCancelBookingSeries(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		toWallet bool
+	}{
+		{"refund to the original method", false},
+		{"refund to the wallet", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := testutil.NewDB(t)
+			fake := testutil.FakeProvider(t)
+			user := testutil.CreateUser(t, db, "player@example.com")
+			field := testutil.CreateField(t, db, 100000)
+			start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+			series, bookings := createTestSeries(t, db, user, field, start, 3)
+
+			// The first occurrence is paid, the others are still held; one
+			// earlier occurrence has already been played
+			paid := &bookings[0]
+			if err := db.Model(paid).Update("status", "confirmed").Error; err != nil {
+				t.Fatalf("confirm booking: %v", err)
+			}
+			payment := openTestCheckout(t, db, fake, paid, true)
+			past := time.Now().Add(-7 * 24 * time.Hour).Truncate(time.Hour)
+			played := createTestBooking(t, db, user, field, past, past.Add(time.Hour), "confirmed")
+			if err := db.Model(played).Update("series_id", series.ID).Error; err != nil {
+				t.Fatalf("add past occurrence: %v", err)
+			}
+
+			results, err := CancelBookingSeries(db, series, tc.toWallet)
+			if err != nil {
+				t.Fatalf("cancel series: %v", err)
+			}
+			if len(results) != len(bookings) {
+				t.Fatalf("%d occurrences cancelled, want the %d upcoming ones", len(results), len(bookings))
+			}
+			for _, r := range results {
+				if r.Err != nil {
+					t.Fatalf("cancel booking %s: %v", r.Booking.ID, r.Err)
+				}
+				if r.Result.Refunded != (r.Booking.ID == paid.ID) {
+					t.Errorf("booking %s refunded %v", r.Booking.ID, r.Result.Refunded)
+				}
+				if b := loadBooking(t, db, r.Booking.ID); b.Status != "cancelled" {
+					t.Errorf("booking %s is %s, want cancelled", b.ID, b.Status)
+				}
+			}
+			if b := loadBooking(t, db, played.ID); b.Status != "confirmed" {
+				t.Errorf("past occurrence is %s, want it left confirmed", b.Status)
+			}
+
+			p := loadPayment(t, db, payment.ID)
+			if p.Status != "refunded" || p.RefundedAmount != payment.Amount {
+				t.Errorf("payment is %s with %v refunded, want refunded in full", p.Status, p.RefundedAmount)
+			}
+			want := 1
+			if tc.toWallet {
+				want = 0
+			}
+			if refunds := fake.Refunds(payment.StripeRefID); len(refunds) != want {
+				t.Errorf("%d provider refunds, want %d", len(refunds), want)
+			}
+
+			var stored models.BookingSeries
+			if err := db.First(&stored, "id = ?", series.ID).Error; err != nil {
+				t.Fatalf("load series: %v", err)
+			}
+			if stored.Status != "cancelled" {
+				t.Fatalf("series is %s, want cancelled", stored.Status)
+			}
+		})
+	}
+}
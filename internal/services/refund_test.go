@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestProviderRefundAfterWalletRefund(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "confirmed")
+	payment := openTestCheckout(t, db, fake, booking, true)
+	if err := db.Model(payment).Update("payment_intent_id", "pi_test").Error; err != nil {
+		t.Fatalf("set payment intent: %v", err)
+	}
+
+	if _, err := RefundToWallet(db, payment, 40000); err != nil {
+		t.Fatalf("refund to wallet: %v", err)
+	}
+
+	// The provider refunds part of the charge on its own
+	err := ApplyProviderRefund(db, &payments.Event{
+		Type:            payments.EventChargeRefunded,
+		PaymentIntentID: "pi_test",
+		AmountRefunded:  ToMinorUnits(20000, payment.Currency),
+	})
+	if err != nil {
+		t.Fatalf("apply provider refund: %v", err)
+	}
+
+	var p models.Payment
+	if err := db.First(&p, "id = ?", payment.ID).Error; err != nil {
+		t.Fatalf("load payment: %v", err)
+	}
+	if p.RefundedAmount != 60000 || p.WalletRefundedAmount != 40000 || p.Status != "succeeded" {
+		t.Fatalf("payment is %s with %v refunded, %v to the wallet; want succeeded with 60000, 40000 to the wallet",
+			p.Status, p.RefundedAmount, p.WalletRefundedAmount)
+	}
+}
+
+func TestReconcileIgnoresWalletRefunds(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "confirmed")
+	payment := openTestCheckout(t, db, fake, booking, true)
+
+	if _, err := RefundToWallet(db, payment, 50000); err != nil {
+		t.Fatalf("refund to wallet: %v", err)
+	}
+
+	report, err := Reconcile(db, time.Now().Add(-time.Hour), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if report.Checked != 1 || len(report.Discrepancies) != 0 {
+		t.Fatalf("reconcile checked %d sessions with discrepancies %+v, want 1 without", report.Checked, report.Discrepancies)
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotReschedulable  = errors.New("only bookings that are not cancelled and have not started can be rescheduled")
+	ErrCheckoutCompleted = errors.New("a payment for this booking was just completed, try again shortly")
+	ErrSettlementFailed  = errors.New("booking was rescheduled but settling the price difference failed, reschedule it to the same time again to retry")
+)
+
+// RescheduleResult describes the price change of a rescheduled booking.
+type RescheduleResult struct {
+	PreviousTotal  float64
+	AmountDue      float64 // top-up still to pay through CheckoutURL
+	RefundedAmount float64
+	RefundID       string
+	CheckoutID     string
+	CheckoutURL    string
+}
+
+// RescheduleBooking moves a booking (with Payments preloaded) to [start, end)
+// on field, which may differ from the booking's current field. The slot is
+// validated like a new booking and the booking is repriced. Open checkout
+// sessions of a pending booking were for the old price and are expired once
+// the new slot is known to be free.
+//
+// For a paid booking the difference is settled after the move is committed:
+// a higher price opens a top-up checkout session, a lower one is partially
+// refunded; the balance of a partially paid booking follows the new price, a
+// deposit exceeding it is refunded. If settling fails the booking stays moved
+// and ErrSettlementFailed is returned with the partial result; rescheduling
+// to the same time again settles what is left.
+func RescheduleBooking(db *gorm.DB, booking *models.Booking, field *models.Field, start, end time.Time) (*RescheduleResult, error) {
+	if booking.Status == "cancelled" || !booking.StartTime.After(time.Now()) {
+		return nil, ErrNotReschedulable
+	}
+
+	if err := CheckOpeningHours(db, field.ID, start, end); err != nil {
+		return nil, err
+	}
+
+	// The shares were agreed on for the current price
+	split, err := IsSplit(db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if split {
+		return nil, ErrBookingSplit
+	}
+
+	quote, err := QuoteBooking(db, field, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFieldID, previousTotal := booking.FieldID, booking.TotalPrice
+	var expired []uuid.UUID
+	err = WithSlotLock(db, field.ID, func(tx *gorm.DB) error {
+		conflict, err := HasConflict(tx, field.ID, start, end, booking.ID)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return ErrSlotTaken
+		}
+
+		for i := range booking.Payments {
+			p := &booking.Payments[i]
+			if p.Status != "pending" {
+				continue
+			}
+			completed, err := ExpireCheckoutSession(p)
+			if err != nil {
+				return err
+			}
+			if completed {
+				return ErrCheckoutCompleted
+			}
+			expired = append(expired, p.ID)
+		}
+		if len(expired) > 0 {
+			if err := tx.Model(&models.Payment{}).Where("id IN ?", expired).Update("status", "expired").Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("booking_id = ?", booking.ID).Delete(&models.BookingPriceItem{}).Error; err != nil {
+			return err
+		}
+		booking.FieldID = field.ID
+		booking.Field = *field
+		booking.StartTime = start
+		booking.EndTime = end
+		ApplyQuote(booking, quote)
+		if err := tx.Create(&booking.PriceItems).Error; err != nil {
+			return err
+		}
+		return tx.Model(booking).Updates(map[string]interface{}{
+			"field_id":    booking.FieldID,
+			"start_time":  booking.StartTime.UTC(),
+			"end_time":    booking.EndTime.UTC(),
+			"total_price": booking.TotalPrice,
+			"currency":    booking.Currency,
+		}).Error
+	})
+	if err != nil {
+		// Sessions already expired with the provider can no longer be paid,
+		// so their payments are released for a new checkout
+		if len(expired) > 0 {
+			if uerr := db.Model(&models.Payment{}).
+				Where("id IN ? AND status = ?", expired, "pending").
+				Update("status", "expired").Error; uerr != nil {
+				log.Printf("❌ Booking %s: failed to release expired checkout payments: %v", booking.ID, uerr)
+			}
+		}
+		return nil, err
+	}
+	for i := range booking.Payments {
+		for _, id := range expired {
+			if booking.Payments[i].ID == id {
+				booking.Payments[i].Status = "expired"
+			}
+		}
+	}
+
+	// The old slot may be wanted by someone on the waitlist
+	OfferWaitlistedSlots(db, oldFieldID)
+
+	result := &RescheduleResult{PreviousTotal: previousTotal}
+	if err := settleReschedule(db, booking, result); err != nil {
+		return result, fmt.Errorf("%w: %v", ErrSettlementFailed, err)
+	}
+	return result, nil
+}
+
+// settleReschedule settles the difference between what was paid for a
+// rescheduled booking and its new price. The payment provider is called
+// outside any transaction and every refund or top-up is recorded in its own
+// write, so nothing that happened with the provider is rolled back.
+// A promo discount counts as paid.
+func settleReschedule(db *gorm.DB, booking *models.Booking, result *RescheduleResult) error {
+	// Unpaid bookings are simply paid at the new price
+	paid := PaidAmount(booking.Payments)
+	if booking.Status == "pending" || paid <= 0 {
+		return nil
+	}
+
+	diff := RoundAmount(booking.TotalPrice-paid-Discounts(booking.Payments), booking.Currency)
+	switch {
+	case diff > 0 && booking.Status == "partially_paid":
+		// Paid with the balance
+	case diff > 0:
+		return openTopUp(db, booking, diff, result)
+	case diff < 0:
+		return refundDifference(db, booking, -diff, result)
+	}
+	return nil
+}
+
+// openTopUp opens a checkout session for the extra amount of a rescheduled
+// booking and records its pending payment. The top-up goes through the
+// provider the booking was paid with.
+func openTopUp(db *gorm.DB, booking *models.Booking, amount float64, result *RescheduleResult) error {
+	name := fmt.Sprintf("%s - reschedule to %s", booking.Field.Name, booking.StartTime.In(config.Location).Format("02 Jan 2006 15:04"))
+	provider := payments.Default
+	for i := len(booking.Payments) - 1; i >= 0; i-- {
+		if p := booking.Payments[i]; p.Status == "succeeded" && p.Provider != WalletProvider {
+			if paidWith, err := payments.Get(p.Provider); err == nil {
+				provider = paidWith
+			}
+			break
+		}
+	}
+	s, err := NewCheckoutSession(provider, booking.Currency, []CheckoutItem{{Name: name, Amount: amount}}, map[string]string{
+		"booking_id": booking.ID.String(),
+		"purpose":    "reschedule_top_up",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create top-up checkout session: %w", err)
+	}
+
+	payment := models.Payment{
+		BookingID:   booking.ID,
+		Amount:      amount,
+		Currency:    booking.Currency,
+		Status:      "pending",
+		StripeRefID: s.ID,
+		Provider:    provider.Name(),
+	}
+	if err := db.Create(&payment).Error; err != nil {
+		// An unrecorded session must not be paid
+		if _, xerr := provider.ExpireCheckoutSession(s.ID); xerr != nil {
+			log.Printf("❌ Booking %s: failed to expire unrecorded top-up session %s: %v", booking.ID, s.ID, xerr)
+		}
+		return err
+	}
+
+	result.AmountDue = amount
+	result.CheckoutID = s.ID
+	result.CheckoutURL = s.URL
+	return nil
+}
+
+// refundDifference refunds amount of a rescheduled booking, starting with
+// its latest succeeded payment. Each refund is recorded on its payment right
+// away, so a failure halfway leaves only the rest to refund on retry.
+func refundDifference(db *gorm.DB, booking *models.Booking, amount float64, result *RescheduleResult) error {
+	left := amount
+	for i := len(booking.Payments) - 1; i >= 0 && left > 0; i-- {
+		p := &booking.Payments[i]
+		if p.Status != "succeeded" {
+			continue
+		}
+		part := RoundAmount(min(left, p.Amount-p.RefundedAmount), p.Currency)
+		if part <= 0 {
+			continue
+		}
+		ref, err := RefundPayment(db, p, part)
+		if err != nil {
+			return err
+		}
+		left = RoundAmount(left-part, booking.Currency)
+		result.RefundedAmount = RoundAmount(result.RefundedAmount+part, booking.Currency)
+		result.RefundID = ref.ID
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+func TestRescheduleToTakenSlotKeepsCheckoutOpen(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	payment := openTestCheckout(t, db, fake, booking, false)
+	createTestBooking(t, db, user, field, start.Add(3*time.Hour), start.Add(4*time.Hour), "confirmed")
+
+	_, err := RescheduleBooking(db, booking, field, start.Add(3*time.Hour), start.Add(4*time.Hour))
+	if !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("got %v, want ErrSlotTaken", err)
+	}
+
+	s, err := fake.GetCheckoutSession(payment.StripeRefID)
+	if err != nil {
+		t.Fatalf("GetCheckoutSession: %v", err)
+	}
+	if s.Status != payments.SessionOpen {
+		t.Errorf("checkout session is %s, want it still open", s.Status)
+	}
+	var stored models.Payment
+	db.First(&stored, "id = ?", payment.ID)
+	if stored.Status != "pending" {
+		t.Errorf("payment is %s, want pending", stored.Status)
+	}
+}
+
+func TestReschedulePendingExpiresCheckout(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	payment := openTestCheckout(t, db, fake, booking, false)
+
+	if _, err := RescheduleBooking(db, booking, field, start.Add(2*time.Hour), start.Add(4*time.Hour)); err != nil {
+		t.Fatalf("RescheduleBooking: %v", err)
+	}
+
+	s, _ := fake.GetCheckoutSession(payment.StripeRefID)
+	if s.Status != payments.SessionExpired {
+		t.Errorf("checkout session is %s, want expired", s.Status)
+	}
+	var stored models.Payment
+	db.First(&stored, "id = ?", payment.ID)
+	if stored.Status != "expired" {
+		t.Errorf("payment is %s, want expired", stored.Status)
+	}
+}
+
+func TestRescheduleCheaperRefundsDifference(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(2*time.Hour), "confirmed")
+	payment := openTestCheckout(t, db, fake, booking, true)
+
+	result, err := RescheduleBooking(db, booking, field, start.Add(5*time.Hour), start.Add(6*time.Hour))
+	if err != nil {
+		t.Fatalf("RescheduleBooking: %v", err)
+	}
+	if result.RefundedAmount != 100000 || result.PreviousTotal != 200000 {
+		t.Fatalf("refunded %v of previous total %v, want 100000 of 200000", result.RefundedAmount, result.PreviousTotal)
+	}
+	if refunds := fake.Refunds(payment.StripeRefID); len(refunds) != 1 {
+		t.Fatalf("provider issued %d refunds, want 1", len(refunds))
+	}
+
+	var stored models.Payment
+	db.First(&stored, "id = ?", payment.ID)
+	if stored.RefundedAmount != 100000 {
+		t.Errorf("recorded refund %v, want 100000", stored.RefundedAmount)
+	}
+}
+
+func TestRescheduleSettlementFailureKeepsMove(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	field := testutil.CreateField(t, db, 100000)
+	user := testutil.CreateUser(t, db, "booker@example.com")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(2*time.Hour), "confirmed")
+	payment := openTestCheckout(t, db, fake, booking, true)
+
+	// The provider no longer knows the session, so the refund fails
+	db.Model(payment).Update("stripe_ref_id", "fake_cs_unknown")
+	booking.Payments[0].StripeRefID = "fake_cs_unknown"
+
+	newStart := start.Add(5 * time.Hour)
+	_, err := RescheduleBooking(db, booking, field, newStart, newStart.Add(time.Hour))
+	if !errors.Is(err, ErrSettlementFailed) {
+		t.Fatalf("got %v, want ErrSettlementFailed", err)
+	}
+
+	var stored models.Booking
+	db.First(&stored, "id = ?", booking.ID)
+	if !stored.StartTime.Equal(newStart) || stored.TotalPrice != 100000 {
+		t.Errorf("booking at %v for %v, want it moved to %v for 100000", stored.StartTime, stored.TotalPrice, newStart)
+	}
+	var storedPayment models.Payment
+	db.First(&storedPayment, "id = ?", payment.ID)
+	if storedPayment.RefundedAmount != 0 {
+		t.Errorf("recorded refund %v although the provider refused it", storedPayment.RefundedAmount)
+	}
+}
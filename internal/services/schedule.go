@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFieldClosed         = errors.New("field is closed on the requested date")
+	ErrOutsideOpeningHours = errors.New("booking is outside the field's opening hours")
+)
+
+// Interval is a half-open time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether [start, end) lies completely inside the interval.
+func (i Interval) Contains(start, end time.Time) bool {
+	return !start.Before(i.Start) && !end.After(i.End)
+}
+
+// ParseClock parses an "HH:MM" wall clock time into minutes after midnight.
+// "24:00" is accepted as the end of the day.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err == nil {
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+}
+
+// ValidateOpeningHours checks that every interval is well-formed and that
+// intervals on the same weekday do not overlap.
+func ValidateOpeningHours(hours []models.FieldOpeningHour) error {
+	byDay := map[int][][2]int{}
+	for _, h := range hours {
+		if h.Weekday < 0 || h.Weekday > 6 {
+			return fmt.Errorf("invalid weekday %d, expected 0 (Sunday) to 6 (Saturday)", h.Weekday)
+		}
+		open, err := ParseClock(h.OpenTime)
+		if err != nil {
+			return err
+		}
+		closing, err := ParseClock(h.CloseTime)
+		if err != nil {
+			return err
+		}
+		if open >= closing {
+			return fmt.Errorf("open_time %s must be before close_time %s", h.OpenTime, h.CloseTime)
+		}
+		byDay[h.Weekday] = append(byDay[h.Weekday], [2]int{open, closing})
+	}
+
+	for day, ranges := range byDay {
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+		for i := 1; i < len(ranges); i++ {
+			if ranges[i][0] < ranges[i-1][1] {
+				return fmt.Errorf("overlapping opening hours on %s", time.Weekday(day))
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFieldSchedule loads a field with its opening hours and the closures
+// that fall between from and to.
+func LoadFieldSchedule(db *gorm.DB, fieldID uuid.UUID, from, to time.Time) (*models.Field, error) {
+	var field models.Field
+	err := db.
+		Preload("OpeningHours").
+		Preload("Closures", "date >= ? AND date <= ?", startOfDay(from).Format("2006-01-02"), startOfDay(to).Format("2006-01-02")).
+		First(&field, "id = ?", fieldID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// OpenIntervals returns the merged intervals during which the field is open
+// on the days touched by [from, to). Adjacent intervals (e.g. 18:00-24:00 and
+// 00:00-02:00 on the next day) are merged so bookings may cross midnight.
+func OpenIntervals(field *models.Field, from, to time.Time) []Interval {
+	closed := map[string]bool{}
+	for _, cl := range field.Closures {
+		closed[cl.Date] = true
+	}
+
+	var intervals []Interval
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if closed[day.Format("2006-01-02")] {
+			continue
+		}
+
+		if len(field.OpeningHours) == 0 {
+			intervals = appendMerged(intervals, Interval{Start: day, End: day.AddDate(0, 0, 1)})
+			continue
+		}
+
+		var daily []Interval
+		for _, h := range field.OpeningHours {
+			if h.Weekday != int(day.Weekday()) {
+				continue
+			}
+			open, err1 := ParseClock(h.OpenTime)
+			closing, err2 := ParseClock(h.CloseTime)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			daily = append(daily, Interval{
+				Start: day.Add(time.Duration(open) * time.Minute),
+				End:   day.Add(time.Duration(closing) * time.Minute),
+			})
+		}
+		sort.Slice(daily, func(i, j int) bool { return daily[i].Start.Before(daily[j].Start) })
+		for _, in := range daily {
+			intervals = appendMerged(intervals, in)
+		}
+	}
+	return intervals
+}
+
+// CheckOpeningHours verifies that a booking of the field between start and end
+// lies completely within its opening hours and not on a closure day.
+func CheckOpeningHours(db *gorm.DB, fieldID uuid.UUID, start, end time.Time) error {
+	field, err := LoadFieldSchedule(db, fieldID, start, end)
+	if err != nil {
+		return err
+	}
+
+	if withinIntervals(OpenIntervals(field, start, end), start, end) {
+		return nil
+	}
+
+	for _, cl := range field.Closures {
+		day := startOfDay(start)
+		for day.Before(end) {
+			if cl.Date == day.Format("2006-01-02") {
+				return ErrFieldClosed
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	return ErrOutsideOpeningHours
+}
+
+func appendMerged(intervals []Interval, in Interval) []Interval {
+	if n := len(intervals); n > 0 && !in.Start.After(intervals[n-1].End) {
+		if in.End.After(intervals[n-1].End) {
+			intervals[n-1].End = in.End
+		}
+		return intervals
+	}
+	return append(intervals, in)
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Sessions are the logins of a user, one per device. A session has one valid
+// refresh token at a time; refreshing rotates it, and presenting a rotated
+// token again ends the session, since the token must have leaked. Sessions
+// live in Redis:
+//
+//	session:<id>              hash with the user, client and current token hash
+//	sessions:<user id>        set of the user's session IDs
+//	refresh:<token hash>      session of a valid refresh token
+//	refresh_used:<token hash> session of a rotated refresh token
+//
+// Without Redis refresh tokens are not kept and sessions are not tracked.
+
+// RefreshTokenTTL is how long a refresh token is valid. Sessions expire when
+// they have not been refreshed for as long.
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// sessionTouchInterval is how often requests with an access token update
+// when their session was last used.
+const sessionTouchInterval = 5 * time.Minute
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token revoked or expired")
+	// ErrRefreshTokenReused is returned for a refresh token that was already
+	// rotated; its session has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused, session revoked")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionsDisabled   = errors.New("redis not available")
+)
+
+// Session is a login of a user on a device.
+type Session struct {
+	ID         string
+	UserID     string
+	MFA        bool // signed in with a second factor
+	Device     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+
+	tokenHash string
+}
+
+// SessionClient describes the client a session is used from.
+type SessionClient struct {
+	UserAgent string
+	IP        string
+}
+
+func sessionKey(id string) string            { return "session:" + id }
+func userSessionsKey(userID string) string   { return "sessions:" + userID }
+func refreshTokenKey(hash string) string     { return "refresh:" + hash }
+func usedRefreshTokenKey(hash string) string { return "refresh_used:" + hash }
+
+// CreateSession starts a session for a user who just signed in, with mfa
+// telling whether a second factor was used, and returns its ID and first
+// refresh token. Without Redis the session ID is empty.
+func CreateSession(userID string, mfa bool, client SessionClient) (sessionID, refreshToken string, err error) {
+	refreshToken = uuid.NewString()
+	rdb := config.RedisClient
+	if rdb == nil {
+		return "", refreshToken, nil
+	}
+
+	sessionID = uuid.NewString()
+	hash := hashUserToken(refreshToken)
+	now := time.Now().Unix()
+	pipe := rdb.TxPipeline()
+	pipe.HSet(config.Ctx, sessionKey(sessionID), map[string]any{
+		"user_id":      userID,
+		"mfa":          mfa,
+		"user_agent":   client.UserAgent,
+		"ip":           client.IP,
+		"created_at":   now,
+		"last_used_at": now,
+		"token":        hash,
+	})
+	pipe.Expire(config.Ctx, sessionKey(sessionID), RefreshTokenTTL)
+	pipe.Set(config.Ctx, refreshTokenKey(hash), sessionID, RefreshTokenTTL)
+	pipe.SAdd(config.Ctx, userSessionsKey(userID), sessionID)
+	pipe.Expire(config.Ctx, userSessionsKey(userID), RefreshTokenTTL)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return "", "", err
+	}
+	return sessionID, refreshToken, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same
+// session. A token that was rotated before revokes its session and returns
+// ErrRefreshTokenReused.
+func RotateRefreshToken(token string, client SessionClient) (*Session, string, error) {
+	rdb := config.RedisClient
+	if rdb == nil {
+		return nil, "", ErrSessionsDisabled
+	}
+
+	hash := hashUserToken(token)
+	sessionID, err := rdb.GetDel(config.Ctx, refreshTokenKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		if sessionID, err := rdb.Get(config.Ctx, usedRefreshTokenKey(hash)).Result(); err == nil {
+			if s, err := loadSession(sessionID); err == nil {
+				log.Printf("⚠️ Refresh token of session %s of user %s reused, revoking the session", s.ID, s.UserID)
+				if err := revokeSession(s); err != nil {
+					return nil, "", err
+				}
+			}
+			return nil, "", ErrRefreshTokenReused
+		}
+		return rotateLegacyRefreshToken(token, client)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	s, err := loadSession(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.tokenHash != hash {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	newToken := uuid.NewString()
+	newHash := hashUserToken(newToken)
+	s.LastUsedAt, s.IP, s.UserAgent = time.Now(), client.IP, client.UserAgent
+	pipe := rdb.TxPipeline()
+	pipe.Set(config.Ctx, usedRefreshTokenKey(hash), s.ID, RefreshTokenTTL)
+	pipe.Set(config.Ctx, refreshTokenKey(newHash), s.ID, RefreshTokenTTL)
+	pipe.HSet(config.Ctx, sessionKey(s.ID), map[string]any{
+		"token":        newHash,
+		"last_used_at": s.LastUsedAt.Unix(),
+		"ip":           s.IP,
+		"user_agent":   s.UserAgent,
+	})
+	pipe.Expire(config.Ctx, sessionKey(s.ID), RefreshTokenTTL)
+	pipe.Expire(config.Ctx, userSessionsKey(s.UserID), RefreshTokenTTL)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return nil, "", err
+	}
+	s.tokenHash = newHash
+	return s, newToken, nil
+}
+
+// rotateLegacyRefreshToken moves a refresh token issued before sessions were
+// tracked, stored as refresh:<token> with the user ID (and ":mfa" for logins
+// with a second factor), to a new session.
+func rotateLegacyRefreshToken(token string, client SessionClient) (*Session, string, error) {
+	if _, err := uuid.Parse(token); err != nil || len(token) != 36 {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	rdb := config.RedisClient
+	value, err := rdb.GetDel(config.Ctx, "refresh:"+token).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	userID, mfa := strings.CutSuffix(value, ":mfa")
+	rdb.SRem(config.Ctx, legacyUserRefreshTokensKey(userID), token)
+
+	sessionID, newToken, err := CreateSession(userID, mfa, client)
+	if err != nil {
+		return nil, "", err
+	}
+	s, err := loadSession(sessionID)
+	return s, newToken, err
+}
+
+// legacyUserRefreshTokensKey is the set of a user's refresh tokens issued
+// before sessions were tracked.
+func legacyUserRefreshTokensKey(userID string) string { return "refresh_user:" + userID }
+
+// RevokeRefreshToken ends the session of a refresh token, e.g. on logout.
+func RevokeRefreshToken(token string) error {
+	rdb := config.RedisClient
+	if rdb == nil || token == "" {
+		return nil
+	}
+	sessionID, err := rdb.Get(config.Ctx, refreshTokenKey(hashUserToken(token))).Result()
+	if errors.Is(err, redis.Nil) {
+		if _, err := uuid.Parse(token); err == nil {
+			return rdb.Del(config.Ctx, "refresh:"+token).Err()
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s, err := loadSession(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return revokeSession(s)
+}
+
+// ListSessions returns the active sessions of a user, most recently used
+// first.
+func ListSessions(userID string) ([]Session, error) {
+	rdb := config.RedisClient
+	if rdb == nil {
+		return nil, ErrSessionsDisabled
+	}
+	ids, err := rdb.SMembers(config.Ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		s, err := loadSession(id)
+		if errors.Is(err, ErrSessionNotFound) {
+			// Expired without being revoked
+			rdb.SRem(config.Ctx, userSessionsKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// RevokeSession ends a session of a user.
+func RevokeSession(userID, sessionID string) error {
+	if config.RedisClient == nil {
+		return ErrSessionsDisabled
+	}
+	s, err := loadSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if s.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return revokeSession(s)
+}
+
+// RevokeUserSessions ends every session of a user except keep, which may be
+// empty, and revokes their refresh tokens from before sessions were tracked.
+func RevokeUserSessions(userID, keep string) error {
+	rdb := config.RedisClient
+	if rdb == nil {
+		return nil
+	}
+	ids, err := rdb.SMembers(config.Ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if id == keep {
+			continue
+		}
+		s, err := loadSession(id)
+		if errors.Is(err, ErrSessionNotFound) {
+			rdb.SRem(config.Ctx, userSessionsKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := revokeSession(s); err != nil {
+			return err
+		}
+	}
+
+	tokens, err := rdb.SMembers(config.Ctx, legacyUserRefreshTokensKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{legacyUserRefreshTokensKey(userID)}
+	for _, t := range tokens {
+		keys = append(keys, "refresh:"+t)
+	}
+	return rdb.Del(config.Ctx, keys...).Err()
+}
+
+// TouchSession reports whether the session of an access token is still
+// active and records its use every sessionTouchInterval. Without Redis, or
+// when it fails, sessions are assumed active.
+func TouchSession(sessionID, ip string) bool {
+	rdb := config.RedisClient
+	if rdb == nil {
+		return true
+	}
+	lastUsed, err := rdb.HGet(config.Ctx, sessionKey(sessionID), "last_used_at").Int64()
+	if errors.Is(err, redis.Nil) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	if now := time.Now(); now.Sub(time.Unix(lastUsed, 0)) > sessionTouchInterval {
+		rdb.HSet(config.Ctx, sessionKey(sessionID), "last_used_at", now.Unix(), "ip", ip)
+	}
+	return true
+}
+
+func loadSession(id string) (*Session, error) {
+	fields, err := config.RedisClient.HGetAll(config.Ctx, sessionKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["user_id"] == "" {
+		return nil, ErrSessionNotFound
+	}
+	created, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	lastUsed, _ := strconv.ParseInt(fields["last_used_at"], 10, 64)
+	return &Session{
+		ID:         id,
+		UserID:     fields["user_id"],
+		MFA:        fields["mfa"] == "1",
+		Device:     DeviceName(fields["user_agent"]),
+		UserAgent:  fields["user_agent"],
+		IP:         fields["ip"],
+		CreatedAt:  time.Unix(created, 0),
+		LastUsedAt: time.Unix(lastUsed, 0),
+		tokenHash:  fields["token"],
+	}, nil
+}
+
+func revokeSession(s *Session) error {
+	rdb := config.RedisClient
+	pipe := rdb.TxPipeline()
+	pipe.Del(config.Ctx, sessionKey(s.ID))
+	if s.tokenHash != "" {
+		pipe.Del(config.Ctx, refreshTokenKey(s.tokenHash))
+	}
+	pipe.SRem(config.Ctx, userSessionsKey(s.UserID), s.ID)
+	_, err := pipe.Exec(config.Ctx)
+	return err
+}
+
+// DeviceName is a short description of the client of a user agent, such as
+// "Chrome on Windows".
+func DeviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+	browsers := []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"},
+		{"Safari/", "Safari"}, {"curl/", "curl"}, {"PostmanRuntime/", "Postman"},
+		{"okhttp/", "Android app"}, {"Dart/", "Mobile app"},
+	}
+	systems := []struct{ token, name string }{
+		{"Windows", "Windows"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Android", "Android"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+
+	browser := ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	system := ""
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	if name, _, _ := strings.Cut(userAgent, "/"); len(name) <= 40 {
+		return name
+	}
+	return "Unknown device"
+}
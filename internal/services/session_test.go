@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+var (
+	laptop = SessionClient{UserAgent: "Mozilla/5.0 (Windows NT 10.0) Chrome/126.0 Safari/537.36", IP: "10.0.0.1"}
+	phone  = SessionClient{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5) Safari/604.1", IP: "10.0.0.2"}
+)
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	testutil.Redis(t)
+	userID := uuid.NewString()
+
+	sessionID, first, err := CreateSession(userID, false, laptop)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	s, second, err := RotateRefreshToken(first, laptop)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if s.ID != sessionID || second == first {
+		t.Fatalf("rotated into session %s with token %q, want a new token for %s", s.ID, second, sessionID)
+	}
+
+	// Someone replays the first token after it was rotated
+	if _, _, err := RotateRefreshToken(first, phone); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: %v, want ErrRefreshTokenReused", err)
+	}
+	// Which ends the session for its owner too
+	if _, _, err := RotateRefreshToken(second, laptop); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("rotate the current token: %v, want ErrInvalidRefreshToken", err)
+	}
+	if TouchSession(sessionID, laptop.IP) {
+		t.Fatal("access tokens of the revoked session still accepted")
+	}
+	if sessions, err := ListSessions(userID); err != nil || len(sessions) != 0 {
+		t.Fatalf("sessions %+v (%v), want none", sessions, err)
+	}
+}
+
+func TestLogoutKeepsOtherSessions(t *testing.T) {
+	testutil.Redis(t)
+	userID := uuid.NewString()
+
+	laptopID, laptopToken, err := CreateSession(userID, false, laptop)
+	if err != nil {
+		t.Fatalf("create laptop session: %v", err)
+	}
+	phoneID, phoneToken, err := CreateSession(userID, true, phone)
+	if err != nil {
+		t.Fatalf("create phone session: %v", err)
+	}
+
+	if err := RevokeRefreshToken(laptopToken); err != nil {
+		t.Fatalf("log out: %v", err)
+	}
+
+	sessions, err := ListSessions(userID)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != phoneID || !sessions[0].MFA || sessions[0].Device != "Safari on iOS" {
+		t.Fatalf("sessions %+v, want only the phone's", sessions)
+	}
+	if TouchSession(laptopID, laptop.IP) {
+		t.Fatal("logged out session still accepted")
+	}
+	if !TouchSession(phoneID, phone.IP) {
+		t.Fatal("phone session no longer accepted")
+	}
+	if _, _, err := RotateRefreshToken(laptopToken, laptop); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refresh after logout: %v, want ErrInvalidRefreshToken", err)
+	}
+	if s, _, err := RotateRefreshToken(phoneToken, phone); err != nil || s.ID != phoneID {
+		t.Fatalf("refresh on the phone: %+v (%v), want session %s", s, err, phoneID)
+	}
+}
+
+func TestLegacyRefreshTokenMigrates(t *testing.T) {
+	for _, mfa := range []bool{false, true} {
+		rdb := testutil.Redis(t)
+		userID := uuid.NewString()
+
+		// Issued before sessions were tracked
+		legacy := uuid.NewString()
+		value := userID
+		if mfa {
+			value += ":mfa"
+		}
+		if err := rdb.Set(config.Ctx, "refresh:"+legacy, value, RefreshTokenTTL).Err(); err != nil {
+			t.Fatalf("store legacy token: %v", err)
+		}
+		if err := rdb.SAdd(config.Ctx, legacyUserRefreshTokensKey(userID), legacy).Err(); err != nil {
+			t.Fatalf("store legacy token: %v", err)
+		}
+
+		s, token, err := RotateRefreshToken(legacy, laptop)
+		if err != nil {
+			t.Fatalf("mfa %v: rotate legacy token: %v", mfa, err)
+		}
+		if s.UserID != userID || s.MFA != mfa || token == legacy {
+			t.Fatalf("mfa %v: migrated to %+v, want a new session of %s", mfa, s, userID)
+		}
+		if left, _ := rdb.SMembers(config.Ctx, legacyUserRefreshTokensKey(userID)).Result(); len(left) != 0 {
+			t.Fatalf("mfa %v: legacy tokens %v left, want none", mfa, left)
+		}
+
+		// The legacy token is used up; the new one continues the session
+		if _, _, err := RotateRefreshToken(legacy, laptop); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Fatalf("mfa %v: legacy token again: %v, want ErrInvalidRefreshToken", mfa, err)
+		}
+		next, _, err := RotateRefreshToken(token, laptop)
+		if err != nil || next.ID != s.ID {
+			t.Fatalf("mfa %v: rotate the new token: %+v (%v), want session %s", mfa, next, err, s.ID)
+		}
+		if sessions, err := ListSessions(userID); err != nil || len(sessions) != 1 {
+			t.Fatalf("mfa %v: sessions %+v (%v), want the migrated one", mfa, sessions, err)
+		}
+	}
+}
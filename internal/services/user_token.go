@@ -0,0 +1,118 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Purposes of single-use user tokens.
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+	TokenMFAChallenge      = "mfa_challenge"
+	TokenMFAEnrollment     = "mfa_enrollment"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+func userTokenKey(purpose, hash string) string { return "user_token:" + purpose + ":" + hash }
+
+// latestUserTokenKey holds the hash of the last token issued to a user for a
+// purpose, so issuing a new one revokes it.
+func latestUserTokenKey(purpose string, userID uuid.UUID) string {
+	return "user_token:" + purpose + ":user:" + userID.String()
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueUserToken creates a single-use token for purpose that expires after
+// ttl and revokes the user's earlier tokens for it. The token's hash is
+// stored in Redis when available, otherwise in the database.
+func IssueUserToken(db *gorm.DB, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	hash := hashUserToken(token)
+
+	if err := db.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Delete(&models.UserToken{}).Error; err != nil {
+		return "", err
+	}
+
+	if rdb := config.RedisClient; rdb != nil {
+		latest := latestUserTokenKey(purpose, userID)
+		if prev, err := rdb.Get(config.Ctx, latest).Result(); err == nil {
+			rdb.Del(config.Ctx, userTokenKey(purpose, prev))
+		}
+		pipe := rdb.TxPipeline()
+		pipe.Set(config.Ctx, userTokenKey(purpose, hash), userID.String(), ttl)
+		pipe.Set(config.Ctx, latest, hash, ttl)
+		if _, err := pipe.Exec(config.Ctx); err == nil {
+			return token, nil
+		}
+	}
+
+	err := db.Create(&models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}).Error
+	return token, err
+}
+
+// ConsumeUserToken uses up a token issued for purpose and returns the user
+// it was issued to.
+func ConsumeUserToken(db *gorm.DB, purpose, token string) (uuid.UUID, error) {
+	hash := hashUserToken(token)
+
+	if rdb := config.RedisClient; rdb != nil {
+		userID, err := rdb.GetDel(config.Ctx, userTokenKey(purpose, hash)).Result()
+		if err == nil {
+			uid, err := uuid.Parse(userID)
+			if err != nil {
+				return uuid.Nil, ErrInvalidToken
+			}
+			rdb.Del(config.Ctx, latestUserTokenKey(purpose, uid))
+			return uid, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return uuid.Nil, err
+		}
+	}
+
+	// Issued while Redis was unavailable
+	var t models.UserToken
+	if err := db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, err
+	}
+	res := db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", t.ID).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return t.UserID, nil
+}
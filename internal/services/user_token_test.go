@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+)
+
+// withoutRedis stores user tokens in the database for the test.
+func withoutRedis(t *testing.T) {
+	t.Helper()
+
+	prev := config.RedisClient
+	config.RedisClient = nil
+	t.Cleanup(func() { config.RedisClient = prev })
+}
+
+func TestConsumeUserTokenOnce(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+
+	token, err := IssueUserToken(db, user.ID, TokenPasswordReset, time.Hour)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	// Tokens are bound to their purpose
+	if _, err := ConsumeUserToken(db, TokenEmailVerification, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("consume for another purpose: %v, want ErrInvalidToken", err)
+	}
+	uid, err := ConsumeUserToken(db, TokenPasswordReset, token)
+	if err != nil || uid != user.ID {
+		t.Fatalf("consume: user %s (%v), want %s", uid, err, user.ID)
+	}
+	if _, err := ConsumeUserToken(db, TokenPasswordReset, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("consume twice: %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestConsumeUserTokenExpired(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+
+	token, err := IssueUserToken(db, user.ID, TokenEmailVerification, time.Hour)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	if err := db.Model(&models.UserToken{}).Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire token: %v", err)
+	}
+	if _, err := ConsumeUserToken(db, TokenEmailVerification, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("consume expired token: %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestIssueUserTokenRevokesEarlier(t *testing.T) {
+	withoutRedis(t)
+	db := testutil.NewDB(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+
+	first, err := IssueUserToken(db, user.ID, TokenPasswordReset, time.Hour)
+	if err != nil {
+		t.Fatalf("issue first token: %v", err)
+	}
+	second, err := IssueUserToken(db, user.ID, TokenPasswordReset, time.Hour)
+	if err != nil {
+		t.Fatalf("issue second token: %v", err)
+	}
+	if _, err := ConsumeUserToken(db, TokenPasswordReset, first); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("consume revoked token: %v, want ErrInvalidToken", err)
+	}
+	if _, err := ConsumeUserToken(db, TokenPasswordReset, second); err != nil {
+		t.Fatalf("consume latest token: %v", err)
+	}
+}
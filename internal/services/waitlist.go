@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/mailer"
+	"github.com/qullDev/BookMyField/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSlotAvailable     = errors.New("the slot is available, book it directly")
+	ErrAlreadyWaitlisted = errors.New("already on the waitlist for this slot")
+)
+
+// JoinWaitlist queues the entry for its field/time window. Only windows that
+// are currently booked can be waitlisted.
+func JoinWaitlist(db *gorm.DB, entry *models.WaitlistEntry) error {
+	conflict, err := HasConflict(db, entry.FieldID, entry.StartTime, entry.EndTime, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	if !conflict {
+		return ErrSlotAvailable
+	}
+
+	var count int64
+	if err := db.Model(&models.WaitlistEntry{}).
+		Where("user_id = ? AND field_id = ? AND start_time = ? AND end_time = ? AND status IN (?)",
+			entry.UserID, entry.FieldID, entry.StartTime.UTC(), entry.EndTime.UTC(), []string{"waiting", "offered"}).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyWaitlisted
+	}
+
+	entry.Status = "waiting"
+	return db.Create(entry).Error
+}
+
+// ReleaseSlot is called when a booking stops occupying its slot because it
+// was cancelled or expired. A waitlist offer made with the booking is closed
+// and the freed slot is offered to the next waiting users. Failures are only
+// logged: the booking itself has already been released.
+func ReleaseSlot(db *gorm.DB, bookingID, fieldID uuid.UUID) {
+	if err := db.Model(&models.WaitlistEntry{}).
+		Where("booking_id = ? AND status = ?", bookingID, "offered").
+		Update("status", "expired").Error; err != nil {
+		log.Printf("❌ Failed to close waitlist offer of booking %s: %v", bookingID, err)
+	}
+	OfferWaitlistedSlots(db, fieldID)
+}
+
+// OfferWaitlistedSlots offers free windows of a field to waitlisted users in
+// the order they joined. Each offer is a pending booking held for
+// config.WaitlistOfferHold, after which the booking expiry worker cancels it
+// and the slot moves on to the next user.
+func OfferWaitlistedSlots(db *gorm.DB, fieldID uuid.UUID) {
+	var entries []models.WaitlistEntry
+	if err := db.Preload("User").Preload("Field").
+		Where("field_id = ? AND status = ?", fieldID, "waiting").
+		Order("created_at").
+		Find(&entries).Error; err != nil {
+		log.Printf("❌ Failed to load waitlist of field %s: %v", fieldID, err)
+		return
+	}
+
+	now := time.Now()
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.StartTime.After(now) {
+			if err := db.Model(entry).Update("status", "expired").Error; err != nil {
+				log.Printf("❌ Failed to expire waitlist entry %s: %v", entry.ID, err)
+			}
+			continue
+		}
+
+		err := offerSlot(db, entry, now)
+		switch {
+		case err == nil:
+			log.Printf("📣 Waitlist entry %s offered booking %s", entry.ID, *entry.BookingID)
+		case errors.Is(err, ErrSlotTaken), errors.Is(err, ErrFieldClosed), errors.Is(err, ErrOutsideOpeningHours):
+			// still unavailable, keep waiting
+		default:
+			log.Printf("❌ Failed to offer waitlist entry %s: %v", entry.ID, err)
+		}
+	}
+}
+
+// offerSlot books the entry's window for its user as a held pending booking
+// and notifies them.
+func offerSlot(db *gorm.DB, entry *models.WaitlistEntry, now time.Time) error {
+	if err := CheckOpeningHours(db, entry.FieldID, entry.StartTime, entry.EndTime); err != nil {
+		return err
+	}
+	quote, err := QuoteBooking(db, &entry.Field, entry.StartTime, entry.EndTime)
+	if err != nil {
+		return err
+	}
+
+	holdUntil := now.Add(config.WaitlistOfferHold)
+	booking := models.Booking{
+		ID:        uuid.New(),
+		UserID:    entry.UserID,
+		FieldID:   entry.FieldID,
+		StartTime: entry.StartTime,
+		EndTime:   entry.EndTime,
+		Status:    "pending",
+		Notes:     "Waitlist offer",
+		ExpiresAt: &holdUntil,
+	}
+	ApplyQuote(&booking, quote)
+
+	err = WithSlotLock(db, entry.FieldID, func(tx *gorm.DB) error {
+		conflict, err := HasConflict(tx, booking.FieldID, booking.StartTime, booking.EndTime, booking.ID)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return ErrSlotTaken
+		}
+		if err := tx.Create(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Model(entry).Updates(map[string]interface{}{
+			"status":           "offered",
+			"booking_id":       booking.ID,
+			"offered_at":       now,
+			"offer_expires_at": holdUntil,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	entry.BookingID = &booking.ID
+
+	start := entry.StartTime.In(config.Location)
+	body := fmt.Sprintf("Hi %s,\n\n%s is now available on %s, %s-%s, the slot you were waiting for.\n"+
+		"We are holding it for you as booking %s until %s. Pay for the booking before then to keep it;\n"+
+		"afterwards the slot is offered to the next person on the waitlist.\n",
+		entry.User.Name, entry.Field.Name, start.Format("Mon 02 Jan 2006"), start.Format("15:04"),
+		entry.EndTime.In(config.Location).Format("15:04"), booking.ID, holdUntil.In(config.Location).Format("02 Jan 15:04"))
+	if err := mailer.Send(entry.User.Email, "Your waitlisted slot is available", body); err != nil {
+		log.Printf("⚠️ Failed to notify %s about waitlist offer %s: %v", entry.User.Email, entry.ID, err)
+	}
+	return nil
+}
+
+// ExpireWaitlistEntries closes waiting entries whose window has started.
+func ExpireWaitlistEntries(db *gorm.DB, now time.Time) error {
+	return db.Model(&models.WaitlistEntry{}).
+		Where("status = ? AND start_time <= ?", "waiting", now.UTC()).
+		Update("status", "expired").Error
+}
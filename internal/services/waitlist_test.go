@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// joinTestWaitlist puts the user on the waitlist for [start, end), having
+// joined at joined.
+func joinTestWaitlist(t *testing.T, db *gorm.DB, user *models.User, field *models.Field, start, end, joined time.Time) *models.WaitlistEntry {
+	t.Helper()
+
+	entry := &models.WaitlistEntry{UserID: user.ID, FieldID: field.ID, StartTime: start, EndTime: end, CreatedAt: joined}
+	if err := JoinWaitlist(db, entry); err != nil {
+		t.Fatalf("join waitlist: %v", err)
+	}
+	return entry
+}
+
+func loadWaitlistEntry(t *testing.T, db *gorm.DB, id interface{}) *models.WaitlistEntry {
+	t.Helper()
+
+	var entry models.WaitlistEntry
+	if err := db.First(&entry, "id = ?", id).Error; err != nil {
+		t.Fatalf("load waitlist entry: %v", err)
+	}
+	return &entry
+}
+
+func TestWaitlistOffersInJoinOrder(t *testing.T) {
+	db := testutil.NewDB(t)
+	mail := recordMail(t)
+	owner := testutil.CreateUser(t, db, "owner@example.com")
+	first := testutil.CreateUser(t, db, "first@example.com")
+	second := testutil.CreateUser(t, db, "second@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	end := start.Add(time.Hour)
+	booking := createTestBooking(t, db, owner, field, start, end, "confirmed")
+
+	// The second user joins with an earlier timestamp than the first
+	now := time.Now()
+	late := joinTestWaitlist(t, db, first, field, start, end, now)
+	early := joinTestWaitlist(t, db, second, field, start, end, now.Add(-time.Minute))
+
+	if _, err := CancelBooking(db, booking, false); err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+
+	offered := loadWaitlistEntry(t, db, early.ID)
+	if offered.Status != "offered" || offered.BookingID == nil || offered.OfferExpiresAt == nil {
+		t.Fatalf("earliest entry is %s with booking %v, want offered", offered.Status, offered.BookingID)
+	}
+	if b := loadBooking(t, db, *offered.BookingID); b.Status != "pending" || b.UserID != second.ID {
+		t.Fatalf("offered booking is %s for %s, want pending for %s", b.Status, b.UserID, second.ID)
+	}
+	if waiting := loadWaitlistEntry(t, db, late.ID); waiting.Status != "waiting" {
+		t.Fatalf("later entry is %s, want waiting", waiting.Status)
+	}
+	if len(mail.to) != 1 || mail.to[0] != second.Email {
+		t.Fatalf("notified %v, want only %s", mail.to, second.Email)
+	}
+}
+
+func TestWaitlistOfferMovesOnAfterHold(t *testing.T) {
+	db := testutil.NewDB(t)
+	recordMail(t)
+	owner := testutil.CreateUser(t, db, "owner@example.com")
+	first := testutil.CreateUser(t, db, "first@example.com")
+	second := testutil.CreateUser(t, db, "second@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	end := start.Add(time.Hour)
+	booking := createTestBooking(t, db, owner, field, start, end, "confirmed")
+
+	now := time.Now()
+	a := joinTestWaitlist(t, db, first, field, start, end, now.Add(-time.Minute))
+	b := joinTestWaitlist(t, db, second, field, start, end, now)
+	if _, err := CancelBooking(db, booking, false); err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	held := *loadWaitlistEntry(t, db, a.ID).BookingID
+
+	// The first user lets the hold run out
+	expired, err := ExpiredPendingBookings(db, time.Now().Add(config.WaitlistOfferHold+time.Minute))
+	if err != nil {
+		t.Fatalf("load expired bookings: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != held {
+		t.Fatalf("%d bookings past their hold, want the offered one", len(expired))
+	}
+	if ok, err := ExpireBooking(db, &expired[0]); err != nil || !ok {
+		t.Fatalf("expire booking: %v, %v", ok, err)
+	}
+
+	if e := loadWaitlistEntry(t, db, a.ID); e.Status != "expired" {
+		t.Fatalf("first entry is %s after its hold, want expired", e.Status)
+	}
+	next := loadWaitlistEntry(t, db, b.ID)
+	if next.Status != "offered" || next.BookingID == nil || *next.BookingID == held {
+		t.Fatalf("second entry is %s with booking %v, want offered a new booking", next.Status, next.BookingID)
+	}
+}
+
+func TestWaitlistOfferBookedWhenPaid(t *testing.T) {
+	db := testutil.NewDB(t)
+	recordMail(t)
+	fake := testutil.FakeProvider(t)
+	owner := testutil.CreateUser(t, db, "owner@example.com")
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	end := start.Add(time.Hour)
+	booking := createTestBooking(t, db, owner, field, start, end, "confirmed")
+
+	entry := joinTestWaitlist(t, db, user, field, start, end, time.Now())
+	if _, err := CancelBooking(db, booking, false); err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	offer := loadBooking(t, db, *loadWaitlistEntry(t, db, entry.ID).BookingID)
+
+	payment := openTestCheckout(t, db, fake, offer, false)
+	if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+		t.Fatalf("mark checkout succeeded: %v", err)
+	}
+
+	if e := loadWaitlistEntry(t, db, entry.ID); e.Status != "booked" {
+		t.Fatalf("entry is %s after payment, want booked", e.Status)
+	}
+	if b := loadBooking(t, db, offer.ID); b.Status != "confirmed" {
+		t.Fatalf("offered booking is %s after payment, want confirmed", b.Status)
+	}
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WalletProvider is the provider name of payments taken from a wallet. A
+// checkout paid partly from the wallet has a wallet payment and a provider
+// payment per booking, sharing the session ID.
+const WalletProvider = "wallet"
+
+// Wallet ledger accounts. The user's side of every transaction is on
+// AccountWallet; the other side is on one of the system accounts.
+const (
+	AccountWallet   = "wallet"
+	AccountTopUps   = "top_ups"  // money received through payment providers
+	AccountBookings = "bookings" // bookings paid from wallets
+	AccountRefunds  = "refunds"  // refunds issued as credit
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient wallet balance")
+	ErrWalletCurrency      = errors.New("wallet is in a different currency")
+)
+
+// GetWallet returns a user's wallet, opening an empty one in the configured
+// currency if the user has none.
+func GetWallet(db *gorm.DB, userID uuid.UUID) (*models.Wallet, error) {
+	wallet := models.Wallet{UserID: userID, Currency: config.Currency}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&wallet).Error; err != nil {
+		return nil, err
+	}
+	if err := db.First(&wallet, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// lockWallet is GetWallet with the row locked for the rest of tx.
+func lockWallet(tx *gorm.DB, userID uuid.UUID) (*models.Wallet, error) {
+	if _, err := GetWallet(tx, userID); err != nil {
+		return nil, err
+	}
+	var wallet models.Wallet
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, "user_id = ?", userID).Error
+	return &wallet, err
+}
+
+// ReservedBalance is what pending wallet payments of a user's open checkouts
+// hold. It is taken from the balance when the checkout succeeds and freed
+// when it expires or fails.
+func ReservedBalance(db *gorm.DB, userID uuid.UUID) (float64, error) {
+	var reserved float64
+	err := db.Model(&models.Payment{}).
+		Joins("JOIN bookings ON bookings.id = payments.booking_id").
+		Where("bookings.user_id = ? AND payments.provider = ? AND payments.status = ?", userID, WalletProvider, "pending").
+		Select("COALESCE(SUM(payments.amount), 0)").
+		Scan(&reserved).Error
+	return reserved, err
+}
+
+// AvailableBalance is the wallet balance minus what open checkouts reserve.
+func AvailableBalance(db *gorm.DB, wallet *models.Wallet) (float64, error) {
+	reserved, err := ReservedBalance(db, wallet.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return max(RoundAmount(wallet.Balance-reserved, wallet.Currency), 0), nil
+}
+
+// WalletPortion locks a user's wallet for the rest of tx and returns how much
+// of due (in currency) it pays: the available balance, at most requested
+// when that is positive.
+func WalletPortion(tx *gorm.DB, userID uuid.UUID, currency string, due, requested float64) (float64, error) {
+	wallet, err := lockWallet(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.EqualFold(wallet.Currency, currency) {
+		return 0, ErrWalletCurrency
+	}
+	available, err := AvailableBalance(tx, wallet)
+	if err != nil {
+		return 0, err
+	}
+	if available <= 0 || requested > available {
+		return 0, ErrInsufficientBalance
+	}
+
+	portion := available
+	if requested > 0 {
+		portion = requested
+	}
+	return RoundAmount(min(portion, due), currency), nil
+}
+
+// postWalletTransaction moves amount between a user's wallet and a system
+// account: positive amounts credit the wallet, negative ones debit it. The
+// wallet must be locked by the caller.
+func postWalletTransaction(tx *gorm.DB, wallet *models.Wallet, amount float64, account, kind, description string, paymentID, topUpID *uuid.UUID) (uuid.UUID, error) {
+	txID := uuid.New()
+	amount = RoundAmount(amount, wallet.Currency)
+	entries := []models.WalletEntry{
+		{Account: AccountWallet, Amount: amount},
+		{Account: account, Amount: -amount},
+	}
+	for i := range entries {
+		entries[i].TransactionID = txID
+		entries[i].UserID = wallet.UserID
+		entries[i].Currency = wallet.Currency
+		entries[i].Kind = kind
+		entries[i].PaymentID = paymentID
+		entries[i].TopUpID = topUpID
+		entries[i].Description = description
+	}
+	if err := tx.Create(&entries).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	wallet.Balance = RoundAmount(wallet.Balance+amount, wallet.Currency)
+	if err := tx.Model(wallet).Update("balance", wallet.Balance).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return txID, nil
+}
+
+// debitWalletPayments takes the pending wallet payments of a checkout
+// session from their users' wallets. The reservation made at checkout
+// covers them, unless the session was paid after it expired; the balance may
+// then go negative, as the provider part has been collected already.
+func debitWalletPayments(tx *gorm.DB, sessionID string) error {
+	var parts []models.Payment
+	if err := tx.Joins("Booking").
+		Where("payments.stripe_ref_id = ? AND payments.provider = ? AND payments.status IN ?",
+			sessionID, WalletProvider, []string{"pending", "expired", "failed"}).
+		Find(&parts).Error; err != nil {
+		return err
+	}
+	for _, p := range parts {
+		wallet, err := lockWallet(tx, p.Booking.UserID)
+		if err != nil {
+			return err
+		}
+		if _, err := postWalletTransaction(tx, wallet, -p.Amount, AccountBookings, "payment",
+			fmt.Sprintf("Booking %s", p.BookingID), &p.ID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefundToWallet refunds amount of a succeeded payment as wallet credit for
+// the booking's user, or for whoever paid the share of a split booking, and
+// records it on the payment like a provider refund. It is also recorded as
+// WalletRefundedAmount, which the provider's refund total does not include.
+func RefundToWallet(db *gorm.DB, payment *models.Payment, amount float64) (*payments.Refund, error) {
+	var txID uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		if err := tx.Select("id", "user_id").First(&booking, "id = ?", payment.BookingID).Error; err != nil {
+			return err
+		}
+		userID := booking.UserID
+		if payment.ShareID != nil {
+			var share models.BookingShare
+			if err := tx.First(&share, "id = ?", *payment.ShareID).Error; err != nil {
+				return err
+			}
+			if share.PaidBy != nil {
+				userID = *share.PaidBy
+			}
+		}
+		wallet, err := lockWallet(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(wallet.Currency, payment.Currency) {
+			return ErrWalletCurrency
+		}
+		txID, err = postWalletTransaction(tx, wallet, amount, AccountRefunds, "refund",
+			fmt.Sprintf("Refund for booking %s", payment.BookingID), &payment.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		payment.RefundedAmount = RoundAmount(payment.RefundedAmount+amount, payment.Currency)
+		payment.WalletRefundedAmount = RoundAmount(payment.WalletRefundedAmount+amount, payment.Currency)
+		if payment.RefundedAmount >= payment.Amount {
+			payment.Status = "refunded"
+		}
+		return tx.Model(payment).Updates(map[string]interface{}{
+			"refunded_amount":        payment.RefundedAmount,
+			"wallet_refunded_amount": payment.WalletRefundedAmount,
+			"status":                 payment.Status,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund to wallet: %w", err)
+	}
+	return &payments.Refund{ID: txID.String(), Status: "succeeded"}, nil
+}
+
+// CreateWalletTopUp opens a checkout session with the provider adding amount
+// to a user's wallet and records the pending top-up.
+func CreateWalletTopUp(db *gorm.DB, provider payments.Provider, userID uuid.UUID, amount float64) (*models.WalletTopUp, *payments.CheckoutSession, error) {
+	wallet, err := GetWallet(db, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	topUp := models.WalletTopUp{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Amount:   RoundAmount(amount, wallet.Currency),
+		Currency: wallet.Currency,
+		Status:   "pending",
+		Provider: provider.Name(),
+	}
+	s, err := NewCheckoutSession(provider, wallet.Currency, []CheckoutItem{{Name: "Wallet top-up", Amount: topUp.Amount}}, map[string]string{
+		"wallet_top_up_id": topUp.ID.String(),
+		"user_id":          userID.String(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	topUp.StripeRefID = s.ID
+	if err := db.Create(&topUp).Error; err != nil {
+		return nil, nil, err
+	}
+	return &topUp, s, nil
+}
+
+// expireWalletTopUps marks the pending top-ups of a checkout session that
+// was not paid as expired or failed.
+func expireWalletTopUps(db *gorm.DB, sessionID, status string) error {
+	return db.Model(&models.WalletTopUp{}).
+		Where("stripe_ref_id = ? AND status = ?", sessionID, "pending").
+		Update("status", status).Error
+}
+
+// creditWalletTopUps credits the wallets topped up through a checkout
+// session. Top-ups paid after they were marked expired are credited too.
+func creditWalletTopUps(tx *gorm.DB, sessionID string) error {
+	var topUps []models.WalletTopUp
+	if err := tx.Where("stripe_ref_id = ? AND status <> ?", sessionID, "succeeded").Find(&topUps).Error; err != nil {
+		return err
+	}
+	for _, t := range topUps {
+		wallet, err := lockWallet(tx, t.UserID)
+		if err != nil {
+			return err
+		}
+		if _, err := postWalletTransaction(tx, wallet, t.Amount, AccountTopUps, "top_up",
+			"Top-up via "+t.Provider, nil, &t.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&t).Update("status", "succeeded").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"github.com/qullDev/BookMyField/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// topUpTestWallet pays a top-up of amount into the user's wallet.
+func topUpTestWallet(t *testing.T, db *gorm.DB, fake *payments.FakeProvider, user *models.User, amount float64) *models.WalletTopUp {
+	t.Helper()
+
+	topUp, s, err := CreateWalletTopUp(db, fake, user.ID, amount)
+	if err != nil {
+		t.Fatalf("create top-up: %v", err)
+	}
+	if _, err := fake.CompleteSession(s.ID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	if err := MarkCheckoutSucceeded(db, s.ID); err != nil {
+		t.Fatalf("mark checkout succeeded: %v", err)
+	}
+	return topUp
+}
+
+// walletBalance returns the user's wallet balance after checking it against
+// the ledger.
+func walletBalance(t *testing.T, db *gorm.DB, user *models.User) float64 {
+	t.Helper()
+
+	wallet, err := GetWallet(db, user.ID)
+	if err != nil {
+		t.Fatalf("load wallet: %v", err)
+	}
+	var ledger float64
+	db.Model(&models.WalletEntry{}).Where("user_id = ? AND account = ?", user.ID, AccountWallet).
+		Select("COALESCE(SUM(amount), 0)").Scan(&ledger)
+	if ledger != wallet.Balance {
+		t.Fatalf("wallet balance %v, but its ledger entries add up to %v", wallet.Balance, ledger)
+	}
+	return wallet.Balance
+}
+
+func TestWalletTopUpCreditedOnce(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+
+	topUp := topUpTestWallet(t, db, fake, user, 200000)
+	// The webhook is delivered again
+	for i := 0; i < 2; i++ {
+		if err := MarkCheckoutSucceeded(db, topUp.StripeRefID); err != nil {
+			t.Fatalf("replay %d: %v", i+1, err)
+		}
+	}
+
+	if balance := walletBalance(t, db, user); balance != 200000 {
+		t.Fatalf("wallet balance %v, want 200000", balance)
+	}
+	var stored models.WalletTopUp
+	if err := db.First(&stored, "id = ?", topUp.ID).Error; err != nil {
+		t.Fatalf("load top-up: %v", err)
+	}
+	if stored.Status != "succeeded" {
+		t.Fatalf("top-up is %s, want succeeded", stored.Status)
+	}
+	var credits int64
+	db.Model(&models.WalletEntry{}).Where("top_up_id = ? AND account = ?", topUp.ID, AccountWallet).Count(&credits)
+	if credits != 1 {
+		t.Fatalf("top-up credited %d times, want once", credits)
+	}
+}
+
+func TestWalletPortion(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	currency := booking.Currency
+
+	if _, err := WalletPortion(db, user.ID, currency, 100000, 0); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("empty wallet: %v, want ErrInsufficientBalance", err)
+	}
+
+	topUpTestWallet(t, db, fake, user, 150000)
+	// An open checkout reserves part of the balance
+	reserved := models.Payment{ID: uuid.New(), BookingID: booking.ID, Amount: 50000, Currency: currency,
+		Status: "pending", StripeRefID: NewReservationRef(), Provider: WalletProvider}
+	if err := db.Create(&reserved).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		due, requested float64
+		want           float64
+		err            error
+	}{
+		{"whole available balance", 300000, 0, 100000, nil},
+		{"capped at the amount due", 80000, 0, 80000, nil},
+		{"requested part", 300000, 30000, 30000, nil},
+		{"more than available", 300000, 120000, 0, ErrInsufficientBalance},
+		{"reserved balance", 300000, 150000, 0, ErrInsufficientBalance},
+	} {
+		got, err := WalletPortion(db, user.ID, currency, tc.due, tc.requested)
+		if !errors.Is(err, tc.err) || got != tc.want {
+			t.Errorf("%s: %v (%v), want %v (%v)", tc.name, got, err, tc.want, tc.err)
+		}
+	}
+
+	other := "EUR"
+	if currency == other {
+		other = "USD"
+	}
+	if _, err := WalletPortion(db, user.ID, other, 100000, 0); !errors.Is(err, ErrWalletCurrency) {
+		t.Fatalf("booking in %s: %v, want ErrWalletCurrency", other, err)
+	}
+}
+
+func TestWalletDebitAndRefund(t *testing.T) {
+	db := testutil.NewDB(t)
+	fake := testutil.FakeProvider(t)
+	user := testutil.CreateUser(t, db, "player@example.com")
+	field := testutil.CreateField(t, db, 100000)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := createTestBooking(t, db, user, field, start, start.Add(time.Hour), "pending")
+	topUpTestWallet(t, db, fake, user, 150000)
+
+	// 60000 from the wallet, the rest through the provider in the same session
+	payment := openTestCheckout(t, db, fake, booking, false)
+	if err := db.Model(payment).Update("amount", booking.TotalPrice-60000).Error; err != nil {
+		t.Fatalf("reduce provider payment: %v", err)
+	}
+	walletPart := models.Payment{ID: uuid.New(), BookingID: booking.ID, Amount: 60000, Currency: booking.Currency,
+		Status: "pending", StripeRefID: payment.StripeRefID, Provider: WalletProvider}
+	if err := db.Create(&walletPart).Error; err != nil {
+		t.Fatalf("create wallet payment: %v", err)
+	}
+	if balance := walletBalance(t, db, user); balance != 150000 {
+		t.Fatalf("wallet balance %v before payment, want 150000", balance)
+	}
+
+	if _, err := fake.CompleteSession(payment.StripeRefID); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := MarkCheckoutSucceeded(db, payment.StripeRefID); err != nil {
+			t.Fatalf("mark checkout succeeded: %v", err)
+		}
+	}
+	if balance := walletBalance(t, db, user); balance != 90000 {
+		t.Fatalf("wallet balance %v after paying, want 90000", balance)
+	}
+
+	// Cancelling refunds the wallet part to the wallet and the rest to the card
+	var paid models.Booking
+	if err := db.Preload("Payments").First(&paid, "id = ?", booking.ID).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	result, err := CancelBooking(db, &paid, false)
+	if err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	if result.RefundedAmount != booking.TotalPrice || !result.ToWallet {
+		t.Fatalf("refunded %v (to the wallet %v), want %v partly to the wallet", result.RefundedAmount, result.ToWallet, booking.TotalPrice)
+	}
+	if balance := walletBalance(t, db, user); balance != 150000 {
+		t.Fatalf("wallet balance %v after the refund, want 150000", balance)
+	}
+	if refunds := fake.Refunds(payment.StripeRefID); len(refunds) != 1 {
+		t.Fatalf("%d provider refunds, want one for the card part", len(refunds))
+	}
+	if p := loadPayment(t, db, payment.ID); p.Status != "refunded" || p.WalletRefundedAmount != 0 {
+		t.Fatalf("card payment is %s with %v refunded to the wallet, want refunded to the card", p.Status, p.WalletRefundedAmount)
+	}
+	if p := loadPayment(t, db, walletPart.ID); p.Status != "refunded" || p.WalletRefundedAmount != 60000 {
+		t.Fatalf("wallet payment is %s with %v refunded to the wallet, want refunded with 60000", p.Status, p.WalletRefundedAmount)
+	}
+}
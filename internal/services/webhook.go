@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrEventProcessed = errors.New("webhook event has already been processed")
+
+// ProcessWebhookEvent stores a verified webhook event and applies it. A
+// redelivery of an event that was already processed is skipped and reported
+// as duplicate; a redelivery of a failed event is processed again.
+func ProcessWebhookEvent(db *gorm.DB, provider string, payload []byte, event *payments.Event) (duplicate bool, err error) {
+	eventID := event.ID
+	if eventID == "" {
+		// Cannot be deduplicated, store it under a unique ID
+		eventID = uuid.NewString()
+	}
+
+	rec := models.WebhookEvent{
+		Provider: provider,
+		EventID:  eventID,
+		Type:     event.Type,
+		Payload:  string(payload),
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rec).Error; err != nil {
+		return false, err
+	}
+	// Load the stored row, which is an earlier one on a redelivery
+	var stored models.WebhookEvent
+	if err := db.Where("provider = ? AND event_id = ?", provider, eventID).First(&stored).Error; err != nil {
+		return false, err
+	}
+
+	return applyWebhookEvent(db, &stored, event)
+}
+
+// ReplayWebhookEvent processes a stored event that has not been processed
+// yet, typically one that failed.
+func ReplayWebhookEvent(db *gorm.DB, rec *models.WebhookEvent) error {
+	if rec.ProcessedAt != nil {
+		return ErrEventProcessed
+	}
+
+	provider, err := payments.Get(rec.Provider)
+	if err != nil {
+		return err
+	}
+	event, err := provider.ParseWebhook([]byte(rec.Payload))
+	if err != nil {
+		recordWebhookError(db, rec, err)
+		return err
+	}
+
+	skipped, err := applyWebhookEvent(db, rec, event)
+	if err != nil {
+		return err
+	}
+	if skipped {
+		return ErrEventProcessed
+	}
+	return nil
+}
+
+// applyWebhookEvent applies an event and marks it processed in one
+// transaction. The event row is locked so concurrent deliveries of the same
+// event are applied once; skipped reports that it had already been processed.
+func applyWebhookEvent(db *gorm.DB, rec *models.WebhookEvent, event *payments.Event) (skipped bool, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var locked models.WebhookEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", rec.ID).Error; err != nil {
+			return err
+		}
+		if locked.ProcessedAt != nil {
+			skipped = true
+			return nil
+		}
+
+		if err := HandlePaymentEvent(tx, event); err != nil {
+			return err
+		}
+		return tx.Model(&locked).Updates(map[string]interface{}{
+			"processed_at": time.Now(),
+			"error":        "",
+			"attempts":     gorm.Expr("attempts + 1"),
+		}).Error
+	})
+	if err != nil {
+		recordWebhookError(db, rec, err)
+		return false, err
+	}
+	return skipped, db.First(rec, "id = ?", rec.ID).Error
+}
+
+func recordWebhookError(db *gorm.DB, rec *models.WebhookEvent, err error) {
+	db.Model(rec).Updates(map[string]interface{}{
+		"error":    err.Error(),
+		"attempts": gorm.Expr("attempts + 1"),
+	})
+}
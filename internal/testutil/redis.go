@@ -0,0 +1,274 @@
+package testutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis starts an in-memory Redis server and installs a client for it as
+// config.RedisClient for the duration of the test. The server speaks just
+// enough RESP2 for the commands the services use, with MULTI/EXEC; keys
+// never expire.
+func Redis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &redisServer{
+		strings: map[string]string{},
+		hashes:  map[string]map[string]string{},
+		sets:    map[string]map[string]bool{},
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), Protocol: 2})
+	prev := config.RedisClient
+	config.RedisClient = client
+	t.Cleanup(func() {
+		config.RedisClient = prev
+		client.Close()
+		ln.Close()
+	})
+	return client
+}
+
+type redisServer struct {
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	sets    map[string]map[string]bool
+}
+
+// Replies, written as RESP2 by writeReply
+type (
+	redisStatus string
+	redisError  string
+	redisNil    struct{}
+)
+
+func (s *redisServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	var queued [][]string
+	inMulti := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		var reply any
+		switch name := strings.ToUpper(args[0]); {
+		case name == "MULTI":
+			inMulti, queued, reply = true, nil, redisStatus("OK")
+		case name == "DISCARD":
+			inMulti, queued, reply = false, nil, redisStatus("OK")
+		case name == "EXEC":
+			replies := make([]any, len(queued))
+			s.mu.Lock()
+			for i, cmd := range queued {
+				replies[i] = s.exec(cmd)
+			}
+			s.mu.Unlock()
+			inMulti, queued, reply = false, nil, replies
+		case inMulti:
+			queued, reply = append(queued, args), redisStatus("QUEUED")
+		default:
+			s.mu.Lock()
+			reply = s.exec(args)
+			s.mu.Unlock()
+		}
+		writeReply(w, reply)
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// exec runs a command with s.mu held.
+func (s *redisServer) exec(args []string) any {
+	name, args := strings.ToUpper(args[0]), args[1:]
+	switch name {
+	case "PING":
+		return redisStatus("PONG")
+	case "CLIENT", "SELECT":
+		return redisStatus("OK")
+	case "SET":
+		s.del(args[0])
+		s.strings[args[0]] = args[1]
+		return redisStatus("OK")
+	case "GET", "GETDEL":
+		v, ok := s.strings[args[0]]
+		if !ok {
+			return redisNil{}
+		}
+		if name == "GETDEL" {
+			delete(s.strings, args[0])
+		}
+		return v
+	case "DEL":
+		n := 0
+		for _, key := range args {
+			if s.del(key) {
+				n++
+			}
+		}
+		return n
+	case "EXPIRE":
+		if s.exists(args[0]) {
+			return 1
+		}
+		return 0
+	case "HSET":
+		h := s.hashes[args[0]]
+		if h == nil {
+			h = map[string]string{}
+			s.hashes[args[0]] = h
+		}
+		added := 0
+		for i := 1; i+1 < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				added++
+			}
+			h[args[i]] = args[i+1]
+		}
+		return added
+	case "HGET":
+		v, ok := s.hashes[args[0]][args[1]]
+		if !ok {
+			return redisNil{}
+		}
+		return v
+	case "HGETALL":
+		fields := []any{}
+		for k, v := range s.hashes[args[0]] {
+			fields = append(fields, k, v)
+		}
+		return fields
+	case "SADD":
+		set := s.sets[args[0]]
+		if set == nil {
+			set = map[string]bool{}
+			s.sets[args[0]] = set
+		}
+		added := 0
+		for _, m := range args[1:] {
+			if !set[m] {
+				set[m] = true
+				added++
+			}
+		}
+		return added
+	case "SREM":
+		set := s.sets[args[0]]
+		removed := 0
+		for _, m := range args[1:] {
+			if set[m] {
+				delete(set, m)
+				removed++
+			}
+		}
+		if set != nil && len(set) == 0 {
+			delete(s.sets, args[0])
+		}
+		return removed
+	case "SMEMBERS":
+		members := []any{}
+		for m := range s.sets[args[0]] {
+			members = append(members, m)
+		}
+		return members
+	}
+	return redisError(fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+}
+
+func (s *redisServer) exists(key string) bool {
+	_, str := s.strings[key]
+	_, hash := s.hashes[key]
+	_, set := s.sets[key]
+	return str || hash || set
+}
+
+func (s *redisServer) del(key string) bool {
+	found := s.exists(key)
+	delete(s.strings, key)
+	delete(s.hashes, key)
+	delete(s.sets, key)
+	return found
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("bad array length %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if err != nil || !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("bad bulk string length %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func writeReply(w *bufio.Writer, reply any) {
+	switch v := reply.(type) {
+	case redisStatus:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case redisError:
+		fmt.Fprintf(w, "-%s\r\n", v)
+	case redisNil:
+		w.WriteString("$-1\r\n")
+	case int:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package testutil sets up the database, fixtures and payment provider used
+// by the tests of the other packages.
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/models"
+	"github.com/qullDev/BookMyField/internal/payments"
+	sqlite_driver "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewDB opens a fresh SQLite database with the full schema, installs it as
+// config.DB with config.Location set to UTC and restores both when the test
+// ends.
+func NewDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite_driver.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	err = db.AutoMigrate(&models.User{}, &models.Field{}, &models.Booking{}, &models.Payment{},
+		&models.FieldOpeningHour{}, &models.FieldClosure{}, &models.BookingPriceItem{},
+		&models.PricingRule{}, &models.BookingSeries{},
+		&models.CancellationPolicy{}, &models.WaitlistEntry{}, &models.WebhookEvent{}, &models.Coupon{},
+		&models.Invoice{}, &models.InvoiceCounter{},
+		&models.Wallet{}, &models.WalletEntry{}, &models.WalletTopUp{}, &models.BookingShare{},
+		&models.UserToken{}, &models.UserIdentity{}, &models.OIDCLogin{},
+		&models.RecoveryCode{})
+	if err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	prevDB, prevLoc := config.DB, config.Location
+	config.DB, config.Location = db, time.UTC
+	config.MigrateCancellationPolicyConstraints()
+	t.Cleanup(func() {
+		config.DB, config.Location = prevDB, prevLoc
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// CreateField stores a field charging price per hour.
+func CreateField(t *testing.T, db *gorm.DB, price float64) *models.Field {
+	t.Helper()
+
+	field := &models.Field{Name: "Lapangan Test", Location: "Jakarta", Price: price}
+	if err := db.Create(field).Error; err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	return field
+}
+
+// CreateUser stores a verified user with the given email.
+func CreateUser(t *testing.T, db *gorm.DB, email string) *models.User {
+	t.Helper()
+
+	now := time.Now()
+	user := &models.User{
+		ID:              uuid.New(),
+		Name:            "Test User",
+		Email:           email,
+		Password:        "not-a-real-hash",
+		Role:            "user",
+		EmailVerifiedAt: &now,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// FakeProvider registers a fresh fake payment provider as the default one
+// for the duration of the test.
+func FakeProvider(t *testing.T) *payments.FakeProvider {
+	t.Helper()
+
+	fake := payments.NewFakeProvider("http://localhost:8080")
+	prev := payments.Default
+	payments.Register(fake)
+	payments.Default = fake
+	t.Cleanup(func() { payments.Default = prev })
+	return fake
+}
@@ -0,0 +1,51 @@
+package workers
+
+import (
+	"log"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+const bookingExpiryInterval = time.Minute
+
+// StartBookingExpiryWorker periodically cancels pending bookings whose
+// payment hold (config.BookingHold) has passed, freeing their slots, and
+// closes waitlist entries whose window has started.
+func StartBookingExpiryWorker() {
+	go func() {
+		ticker := time.NewTicker(bookingExpiryInterval)
+		defer ticker.Stop()
+
+		for {
+			ExpirePendingBookings()
+			<-ticker.C
+		}
+	}()
+	log.Println("✅ Booking expiry worker started")
+}
+
+// ExpirePendingBookings runs a single expiry pass.
+func ExpirePendingBookings() {
+	bookings, err := services.ExpiredPendingBookings(config.DB, time.Now())
+	if err != nil {
+		log.Printf("❌ Failed to load expired bookings: %v", err)
+		return
+	}
+
+	for i := range bookings {
+		expired, err := services.ExpireBooking(config.DB, &bookings[i])
+		if err != nil {
+			log.Printf("❌ Failed to expire booking %s: %v", bookings[i].ID, err)
+			continue
+		}
+		if expired {
+			log.Printf("⏰ Booking %s expired after unpaid hold", bookings[i].ID)
+		}
+	}
+
+	if err := services.ExpireWaitlistEntries(config.DB, time.Now()); err != nil {
+		log.Printf("❌ Failed to expire waitlist entries: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package workers
+
+import (
+	"log"
+	"time"
+
+	"github.com/qullDev/BookMyField/internal/config"
+	"github.com/qullDev/BookMyField/internal/services"
+)
+
+// StartReconciliationWorker runs payment reconciliation every night at
+// config.ReconcileHour, fixing payments whose webhooks were missed.
+func StartReconciliationWorker() {
+	if config.ReconcileHour < 0 {
+		log.Println("⚠️ Payment reconciliation disabled (RECONCILE_HOUR=-1)")
+		return
+	}
+
+	go func() {
+		for {
+			time.Sleep(time.Until(nextRun(time.Now(), config.ReconcileHour)))
+			ReconcilePayments()
+		}
+	}()
+	log.Printf("✅ Payment reconciliation scheduled daily at %02d:00", config.ReconcileHour)
+}
+
+// ReconcilePayments runs a single reconciliation pass and logs its report.
+func ReconcilePayments() {
+	since := time.Now().AddDate(0, 0, -config.ReconcileDays)
+	report, err := services.Reconcile(config.DB, since, false)
+	if err != nil {
+		log.Printf("❌ Payment reconciliation failed: %v", err)
+		return
+	}
+
+	for _, d := range report.Discrepancies {
+		log.Printf("🔎 Session %s (%s): %s; local %s, provider %s -> %s (fixed: %t)",
+			d.SessionID, d.Provider, d.Issue, d.LocalStatus, d.ProviderStatus, d.Action, d.Fixed)
+	}
+	log.Printf("✅ Payment reconciliation: %d sessions checked, %d discrepancies, %d unresolved",
+		report.Checked, len(report.Discrepancies), report.Unresolved())
+}
+
+// nextRun returns the next time after now at hour:00 in config.Location.
+func nextRun(now time.Time, hour int) time.Time {
+	local := now.In(config.Location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, config.Location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}